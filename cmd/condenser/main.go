@@ -19,6 +19,12 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// == process reaper ==
+	// must be set before any container is created
+	if err := monitor.EnableSubreaper(); err != nil {
+		log.Fatal(err)
+	}
+
 	// == rest api ==
 	clientCA, err := cert.LoadCertPoolFromFile(utils.ClientIssuerCACertPath)
 	tlsCfg := &tls.Config{
@@ -17,11 +17,57 @@ const docTemplate = `{
     "paths": {
         "/v1/containers": {
             "get": {
-                "description": "get all container list",
+                "description": "get container list. filters are combined with AND",
                 "tags": [
                     "containers"
                 ],
                 "summary": "get container list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label filter (key or key=value)",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "State filter",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image filter (repository[:reference])",
+                        "name": "image",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name prefix",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC3339)",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -90,6 +136,41 @@ const docTemplate = `{
                 }
             }
         },
+        "/v1/containers/{containerId}/actions/commit": {
+            "post": {
+                "description": "create a new image from the container changes",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "commit a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Commit Options",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/container.CommitContainerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/containers/{containerId}/actions/delete": {
             "delete": {
                 "description": "delete an exitsting container",
@@ -151,13 +232,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/containers/{containerId}/actions/start": {
+        "/v1/containers/{containerId}/actions/kill": {
             "post": {
-                "description": "start an exitsting container",
+                "description": "send a signal to the init process of a container",
                 "tags": [
                     "containers"
                 ],
-                "summary": "start a container",
+                "summary": "kill a container",
                 "parameters": [
                     {
                         "type": "string",
@@ -167,18 +248,17 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Start Options",
+                        "description": "Kill Options",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/container.StartContainerRequest"
+                            "$ref": "#/definitions/container.KillContainerRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/utils.ApiResponse"
                         }
@@ -186,13 +266,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/containers/{containerId}/actions/stop": {
+        "/v1/containers/{containerId}/actions/pause": {
             "post": {
-                "description": "stop an exitsting container",
+                "description": "freeze all processes of a running container via cgroup.freeze",
                 "tags": [
                     "containers"
                 ],
-                "summary": "stop a container",
+                "summary": "pause a container",
                 "parameters": [
                     {
                         "type": "string",
@@ -203,8 +283,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/utils.ApiResponse"
                         }
@@ -212,13 +292,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/containers/{containerId}/log": {
-            "get": {
-                "description": "get container log",
+        "/v1/containers/{containerId}/actions/rename": {
+            "post": {
+                "description": "change the name of a container. network policies referring to the old name are updated",
                 "tags": [
                     "containers"
                 ],
-                "summary": "get container log",
+                "summary": "rename a container",
                 "parameters": [
                     {
                         "type": "string",
@@ -226,25 +306,17 @@ const docTemplate = `{
                         "name": "containerId",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    {
+                        "description": "New Name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/utils.ApiResponse"
+                            "$ref": "#/definitions/container.RenameContainerRequest"
                         }
                     }
-                }
-            }
-        },
-        "/v1/hooks/droplet": {
-            "post": {
-                "description": "apply hook from droplet",
-                "tags": [
-                    "hooks"
                 ],
-                "summary": "apply hook",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -255,42 +327,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/images": {
-            "get": {
-                "description": "get image list in local storage",
-                "tags": [
-                    "image"
-                ],
-                "summary": "get image list",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/utils.ApiResponse"
-                        }
-                    }
-                }
-            },
+        "/v1/containers/{containerId}/actions/start": {
             "post": {
-                "description": "pull image from registry",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "start an exitsting container",
                 "tags": [
-                    "image"
+                    "containers"
                 ],
-                "summary": "pull image",
+                "summary": "start a container",
                 "parameters": [
                     {
-                        "description": "Target Image",
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Start Options",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/image.PullImageRequest"
+                            "$ref": "#/definitions/container.StartContainerRequest"
                         }
                     }
                 ],
@@ -302,33 +360,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "remove image from local",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+            }
+        },
+        "/v1/containers/{containerId}/actions/stop": {
+            "post": {
+                "description": "stop an exitsting container. the stop signal is sent first and SIGKILL after the timeout",
                 "tags": [
-                    "image"
+                    "containers"
                 ],
-                "summary": "remove image",
+                "summary": "stop a container",
                 "parameters": [
                     {
-                        "description": "Target Image",
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Stop Options",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/image.RemoveImageRequest"
+                            "$ref": "#/definitions/container.StopContainerRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/utils.ApiResponse"
                         }
@@ -336,24 +396,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/policies": {
-            "get": {
-                "description": "get policy",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+        "/v1/containers/{containerId}/actions/unpause": {
+            "post": {
+                "description": "thaw all processes of a paused container",
                 "tags": [
-                    "Policy"
+                    "containers"
                 ],
-                "summary": "get policy list",
+                "summary": "unpause a container",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Chain Name",
-                        "name": "chainName",
+                        "description": "Container ID",
+                        "name": "containerId",
                         "in": "path",
                         "required": true
                     }
@@ -366,56 +420,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/v1/containers/{containerId}/actions/update": {
             "post": {
-                "description": "Add new policy",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "update resource limits of a running container without restart",
                 "tags": [
-                    "Policy"
+                    "containers"
                 ],
-                "summary": "Add policy",
+                "summary": "update a container",
                 "parameters": [
                     {
-                        "description": "policy parameter",
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resource Limits",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/policy.AddPolicyRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/utils.ApiResponse"
+                            "$ref": "#/definitions/container.UpdateContainerRequest"
                         }
                     }
-                }
-            }
-        },
-        "/v1/policies/commit": {
-            "post": {
-                "description": "commit all policy",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Policy"
                 ],
-                "summary": "commit policy",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/utils.ApiResponse"
                         }
@@ -423,33 +457,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/policies/ns/mode": {
+        "/v1/containers/{containerId}/actions/wait": {
             "post": {
-                "description": "change north-south mode",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "block until the container reaches the condition and return the exit code",
                 "tags": [
-                    "Policy"
+                    "containers"
                 ],
-                "summary": "change north-south mode",
+                "summary": "wait a container",
                 "parameters": [
                     {
-                        "description": "mode",
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Wait Options",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/policy.ChangeNSModeRequest"
+                            "$ref": "#/definitions/container.WaitContainerRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/utils.ApiResponse"
                         }
@@ -457,19 +491,702 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/policies/revert": {
-            "post": {
-                "description": "revert policy",
-                "consumes": [
-                    "application/json"
+        "/v1/containers/{containerId}/archive": {
+            "get": {
+                "description": "stream the file or directory at path in the container rootfs as a tar archive",
+                "produces": [
+                    "application/x-tar"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "get files from a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path in the container",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "extract a tar archive (optionally gzip compressed) into the directory at path in the container rootfs",
+                "consumes": [
+                    "application/x-tar"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "put files into a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Destination directory in the container",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/containers/{containerId}/changes": {
+            "get": {
+                "description": "list files added, modified or deleted in a container against its image",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "get container filesystem changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/containers/{containerId}/export": {
+            "get": {
+                "description": "stream the merged rootfs of a container as a tar archive. gzip=true compresses it",
+                "produces": [
+                    "application/x-tar"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "export a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Gzip compression",
+                        "name": "gzip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/containers/{containerId}/log": {
+            "get": {
+                "description": "get container log",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "get container log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/containers/{containerId}/stats": {
+            "get": {
+                "description": "get resource usage of a container from cgroup. stream=true writes a json line per second until the client disconnects",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "get container stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream stats",
+                        "name": "stream",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/containers/{containerId}/top": {
+            "get": {
+                "description": "list processes running in a container",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "get container processes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "containerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/hooks/droplet": {
+            "post": {
+                "description": "apply hook from droplet",
+                "tags": [
+                    "hooks"
+                ],
+                "summary": "apply hook",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/images": {
+            "get": {
+                "description": "get image list in local storage",
+                "tags": [
+                    "image"
+                ],
+                "summary": "get image list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "pull image from registry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "image"
+                ],
+                "summary": "pull image",
+                "parameters": [
+                    {
+                        "description": "Target Image",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/image.PullImageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "remove image from local",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "image"
+                ],
+                "summary": "remove image",
+                "parameters": [
+                    {
+                        "description": "Target Image",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/image.RemoveImageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/policies": {
+            "get": {
+                "description": "get policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policy"
+                ],
+                "summary": "get policy list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain Name",
+                        "name": "chainName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add new policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policy"
+                ],
+                "summary": "Add policy",
+                "parameters": [
+                    {
+                        "description": "policy parameter",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/policy.AddPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/policies/commit": {
+            "post": {
+                "description": "commit all policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policy"
+                ],
+                "summary": "commit policy",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/policies/ns/mode": {
+            "post": {
+                "description": "change north-south mode",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policy"
+                ],
+                "summary": "change north-south mode",
+                "parameters": [
+                    {
+                        "description": "mode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/policy.ChangeNSModeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/policies/revert": {
+            "post": {
+                "description": "revert policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policy"
+                ],
+                "summary": "revert policy",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/policies/{policyId}": {
+            "delete": {
+                "description": "remove an existing policy",
+                "tags": [
+                    "Policy"
+                ],
+                "summary": "remove a policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Policy ID",
+                        "name": "policyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/security/apparmor": {
+            "get": {
+                "description": "get stored apparmor profiles with the kernel mode and the containers using them",
+                "tags": [
+                    "security"
+                ],
+                "summary": "get apparmor profile list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "validate a profile with apparmor_parser, load it into the kernel and store it. the name must start with \"raind-\". existing profiles are replaced",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "add apparmor profile",
+                "parameters": [
+                    {
+                        "description": "Profile Name and Body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/security.AddAppArmorProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/security/apparmor/validate": {
+            "post": {
+                "description": "check a profile with apparmor_parser without loading or storing it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "validate apparmor profile",
+                "parameters": [
+                    {
+                        "description": "Profile Name and Body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/security.AddAppArmorProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/security/apparmor/{profileName}": {
+            "get": {
+                "description": "get a stored apparmor profile and its source",
+                "tags": [
+                    "security"
+                ],
+                "summary": "get apparmor profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Profile Name",
+                        "name": "profileName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "unload and remove a stored apparmor profile. the built-in profile and profiles in use are refused",
+                "tags": [
+                    "security"
+                ],
+                "summary": "remove apparmor profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Profile Name",
+                        "name": "profileName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/security/apparmor/{profileName}/mode": {
+            "post": {
+                "description": "switch a custom profile between enforce and complain and reload it",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Policy"
+                    "security"
+                ],
+                "summary": "set apparmor profile mode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Profile Name",
+                        "name": "profileName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Profile Mode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/security.SetAppArmorProfileModeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/security/seccomp": {
+            "get": {
+                "description": "get registered seccomp profiles with the containers using them",
+                "tags": [
+                    "security"
+                ],
+                "summary": "get seccomp profile list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "register a seccomp profile in the OCI format. unknown syscall names are rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "add seccomp profile",
+                "parameters": [
+                    {
+                        "description": "Profile Name and Body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/security.AddSeccompProfileRequest"
+                        }
+                    }
                 ],
-                "summary": "revert policy",
                 "responses": {
                     "201": {
                         "description": "Created",
@@ -480,18 +1197,194 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/policies/{policyId}": {
+        "/v1/security/seccomp/{profileName}": {
+            "get": {
+                "description": "get a registered seccomp profile",
+                "tags": [
+                    "security"
+                ],
+                "summary": "get seccomp profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Profile Name",
+                        "name": "profileName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
             "delete": {
-                "description": "remove an existing policy",
+                "description": "remove a registered seccomp profile. the built-in profile and profiles in use are refused",
                 "tags": [
-                    "Policy"
+                    "security"
                 ],
-                "summary": "remove a policy",
+                "summary": "remove seccomp profile",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Policy ID",
-                        "name": "policyId",
+                        "description": "Profile Name",
+                        "name": "profileName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/system/prune": {
+            "post": {
+                "description": "remove stopped containers, unused images and orphaned directories, cgroups and address allocations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "prune unused resources",
+                "parameters": [
+                    {
+                        "description": "Prune Filters",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/system.PruneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/volumes": {
+            "get": {
+                "description": "get named volumes with the containers using them",
+                "tags": [
+                    "volume"
+                ],
+                "summary": "get volume list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a named volume",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volume"
+                ],
+                "summary": "create volume",
+                "parameters": [
+                    {
+                        "description": "Volume Name and Labels",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/volume.CreateVolumeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/volumes/prune": {
+            "post": {
+                "description": "remove named volumes not used by any container",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volume"
+                ],
+                "summary": "prune unused volumes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/volumes/{volumeName}": {
+            "get": {
+                "description": "get a named volume",
+                "tags": [
+                    "volume"
+                ],
+                "summary": "get volume info",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Volume Name",
+                        "name": "volumeName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "remove a named volume and its data. volumes in use by containers are refused",
+                "tags": [
+                    "volume"
+                ],
+                "summary": "remove volume",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Volume Name",
+                        "name": "volumeName",
                         "in": "path",
                         "required": true
                     }
@@ -505,12 +1398,74 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        }
+    },
+    "definitions": {
+        "container.CommitContainerRequest": {
+            "type": "object",
+            "properties": {
+                "cmd": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/bin/sh",
+                        "-c",
+                        "echo hello"
+                    ]
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "add config"
+                },
+                "env": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "MODE=production"
+                    ]
+                },
+                "image": {
+                    "type": "string",
+                    "example": "myapp:v1"
+                }
+            }
+        },
         "container.CreateContainerRequest": {
             "type": "object",
             "properties": {
+                "appArmorProfile": {
+                    "description": "loaded apparmor profile. empty uses raind-default, \"unconfined\" disables apparmor",
+                    "type": "string",
+                    "example": "raind-default"
+                },
+                "autoRemove": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "capAdd": {
+                    "description": "applied to the default capability set. \"ALL\" is accepted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "NET_ADMIN"
+                    ]
+                },
+                "capDrop": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "MKNOD",
+                        "NET_RAW"
+                    ]
+                },
                 "command": {
                     "type": "array",
                     "items": {
@@ -522,16 +1477,82 @@ const docTemplate = `{
                         "echo hello; sleep 60"
                     ]
                 },
+                "dns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1.1.1.1",
+                        "8.8.8.8"
+                    ]
+                },
+                "dnsOptions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ndots:2",
+                        "timeout:1"
+                    ]
+                },
+                "dnsSearch": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "example.internal"
+                    ]
+                },
+                "domainname": {
+                    "type": "string",
+                    "example": "example.internal"
+                },
+                "entrypoint": {
+                    "description": "overrides of the image config. when entrypoint is set, command is passed as its arguments",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/docker-entrypoint.sh"
+                    ]
+                },
                 "env": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "extraHosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "db:10.0.0.5"
+                    ]
+                },
+                "healthcheck": {
+                    "$ref": "#/definitions/container.HealthcheckRequest"
+                },
+                "hostname": {
+                    "description": "hostname defaults to the container id. dns servers default to the host resolv.conf",
+                    "type": "string",
+                    "example": "web"
+                },
                 "image": {
                     "type": "string",
                     "example": "alpine:latest"
                 },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "mount": {
                     "type": "array",
                     "items": {
@@ -550,6 +1571,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "raind0"
                 },
+                "noNewPrivileges": {
+                    "type": "boolean",
+                    "example": true
+                },
                 "port": {
                     "type": "array",
                     "items": {
@@ -560,9 +1585,39 @@ const docTemplate = `{
                         "4443:443"
                     ]
                 },
+                "readOnlyRootfs": {
+                    "description": "mounts the rootfs read-only. /tmp and /run get tmpfs unless mounted by the user",
+                    "type": "boolean",
+                    "example": false
+                },
+                "resources": {
+                    "$ref": "#/definitions/container.ResourceRequest"
+                },
+                "restartPolicy": {
+                    "$ref": "#/definitions/container.RestartPolicyRequest"
+                },
+                "seccompProfile": {
+                    "description": "registered seccomp profile. empty uses raind-default, \"unconfined\" disables seccomp",
+                    "type": "string",
+                    "example": "raind-default"
+                },
+                "tmpfs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/container.TmpfsRequest"
+                    }
+                },
                 "tty": {
                     "type": "boolean",
                     "example": false
+                },
+                "user": {
+                    "type": "string",
+                    "example": "nobody"
+                },
+                "workingDir": {
+                    "type": "string",
+                    "example": "/app"
                 }
             }
         },
@@ -586,6 +1641,106 @@ const docTemplate = `{
                 }
             }
         },
+        "container.HealthcheckRequest": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "type": "string",
+                    "example": "30s"
+                },
+                "restartAfter": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "startPeriod": {
+                    "type": "string",
+                    "example": "10s"
+                },
+                "test": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CMD-SHELL",
+                        "wget -q -O /dev/null http://localhost/ || exit 1"
+                    ]
+                },
+                "timeout": {
+                    "type": "string",
+                    "example": "5s"
+                }
+            }
+        },
+        "container.KillContainerRequest": {
+            "type": "object",
+            "properties": {
+                "signal": {
+                    "type": "string",
+                    "example": "SIGHUP"
+                }
+            }
+        },
+        "container.RenameContainerRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "new-name"
+                }
+            }
+        },
+        "container.ResourceRequest": {
+            "type": "object",
+            "properties": {
+                "cpuMax": {
+                    "type": "string",
+                    "example": "50000 100000"
+                },
+                "cpuWeight": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "ioMax": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "8:0 rbps=1048576 wbps=1048576"
+                    ]
+                },
+                "memoryMax": {
+                    "type": "string",
+                    "example": "512M"
+                },
+                "memorySwapMax": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "pidsMax": {
+                    "type": "string",
+                    "example": "256"
+                }
+            }
+        },
+        "container.RestartPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "maxRetries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "on-failure"
+                }
+            }
+        },
         "container.StartContainerRequest": {
             "type": "object",
             "properties": {
@@ -595,6 +1750,61 @@ const docTemplate = `{
                 }
             }
         },
+        "container.StopContainerRequest": {
+            "type": "object",
+            "properties": {
+                "signal": {
+                    "type": "string",
+                    "example": "SIGTERM"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "container.TmpfsRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "example": "1777"
+                },
+                "noexec": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "size": {
+                    "type": "string",
+                    "example": "64m"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "/cache"
+                }
+            }
+        },
+        "container.UpdateContainerRequest": {
+            "type": "object",
+            "properties": {
+                "resources": {
+                    "$ref": "#/definitions/container.ResourceRequest"
+                }
+            }
+        },
+        "container.WaitContainerRequest": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "type": "string",
+                    "example": "stopped"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
         "image.PullImageRequest": {
             "type": "object",
             "properties": {
@@ -659,6 +1869,71 @@ const docTemplate = `{
                 }
             }
         },
+        "security.AddAppArmorProfileRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "must start with \"raind-\"",
+                    "type": "string",
+                    "example": "raind-web"
+                },
+                "profile": {
+                    "description": "profile in the apparmor_parser syntax. must declare \"profile \u003cname\u003e\"",
+                    "type": "string",
+                    "example": "#include \u003ctunables/global\u003e\nprofile raind-web flags=(attach_disconnected) {\n  #include \u003cabstractions/base\u003e\n  network inet tcp,\n}"
+                }
+            }
+        },
+        "security.AddSeccompProfileRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "web-restricted"
+                },
+                "profile": {
+                    "description": "OCI seccomp profile (linux.seccomp of the runtime spec)",
+                    "type": "object"
+                }
+            }
+        },
+        "security.SetAppArmorProfileModeRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "enforce or complain",
+                    "type": "string",
+                    "example": "complain"
+                }
+            }
+        },
+        "system.PruneRequest": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "string",
+                    "example": "24h"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team=infra"
+                    ]
+                },
+                "states": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "stopped"
+                    ]
+                }
+            }
+        },
         "utils.ApiResponse": {
             "type": "object",
             "properties": {
@@ -671,6 +1946,21 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "volume.CreateVolumeRequest": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "pgdata"
+                }
+            }
         }
     }
 }`
@@ -4,8 +4,15 @@ import (
 	"condenser/internal/core/container"
 	"condenser/internal/store/csm"
 	"condenser/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -13,6 +20,9 @@ import (
 	apimodel "condenser/internal/api/http/utils"
 )
 
+// sampling interval of stats
+const statsInterval = 1 * time.Second
+
 func NewRequestHandler() *RequestHandler {
 	return &RequestHandler{
 		serviceHandler: container.NewContaierService(),
@@ -56,14 +66,45 @@ func (h *RequestHandler) CreateContainer(w http.ResponseWriter, r *http.Request)
 	// service: create
 	result, err := h.serviceHandler.Create(
 		container.ServiceCreateModel{
-			Image:   req.Image,
-			Command: req.Command,
-			Port:    req.Port,
-			Mount:   req.Mount,
-			Env:     req.Env,
-			Network: req.Network,
-			Tty:     req.Tty,
-			Name:    req.Name,
+			Image:           req.Image,
+			Command:         req.Command,
+			Port:            req.Port,
+			Mount:           req.Mount,
+			Env:             req.Env,
+			Network:         req.Network,
+			Tty:             req.Tty,
+			Name:            req.Name,
+			Entrypoint:      req.Entrypoint,
+			User:            req.User,
+			WorkingDir:      req.WorkingDir,
+			Hostname:        req.Hostname,
+			Domainname:      req.Domainname,
+			Dns:             req.Dns,
+			DnsSearch:       req.DnsSearch,
+			DnsOptions:      req.DnsOptions,
+			ExtraHosts:      req.ExtraHosts,
+			ReadOnlyRootfs:  req.ReadOnlyRootfs,
+			Tmpfs:           toTmpfsModel(req.Tmpfs),
+			CapAdd:          req.CapAdd,
+			CapDrop:         req.CapDrop,
+			NoNewPrivileges: req.NoNewPrivileges,
+			SeccompProfile:  req.SeccompProfile,
+			AppArmorProfile: req.AppArmorProfile,
+			Resources:       toResourceModel(req.Resources),
+			RestartPolicy: container.RestartPolicyModel{
+				Name:       req.RestartPolicy.Name,
+				MaxRetries: req.RestartPolicy.MaxRetries,
+			},
+			Healthcheck: container.HealthcheckModel{
+				Test:         req.Healthcheck.Test,
+				Interval:     req.Healthcheck.Interval,
+				Timeout:      req.Healthcheck.Timeout,
+				StartPeriod:  req.Healthcheck.StartPeriod,
+				Retries:      req.Healthcheck.Retries,
+				RestartAfter: req.Healthcheck.RestartAfter,
+			},
+			AutoRemove: req.AutoRemove,
+			Labels:     req.Labels,
 		},
 	)
 	if err != nil {
@@ -71,6 +112,15 @@ func (h *RequestHandler) CreateContainer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// set log: resolved security profiles
+	if containerInfo, err := h.csmHandler.GetContainerById(result); err == nil {
+		logger.SetTarget(r.Context(), logger.Target{
+			ContainerId:     containerInfo.ContainerId,
+			SeccompProfile:  containerInfo.SeccompProfile,
+			AppArmorProfile: containerInfo.AppArmorProfile,
+		})
+	}
+
 	// encode response
 	apimodel.RespondSuccess(w, http.StatusOK, "cotainer created", CreateContainerResponse{Id: result})
 }
@@ -99,10 +149,17 @@ func (h *RequestHandler) StartContainer(w http.ResponseWriter, r *http.Request)
 
 	// set log: target
 	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	var log_seccompProfile, log_appArmorProfile string
+	if containerInfo, err := h.csmHandler.GetContainerById(log_containerId); err == nil {
+		log_seccompProfile = containerInfo.SeccompProfile
+		log_appArmorProfile = containerInfo.AppArmorProfile
+	}
 	logger.SetTarget(r.Context(), logger.Target{
-		ContainerId:   log_containerId,
-		ContainerName: log_containerName,
-		Tty:           req.Tty,
+		ContainerId:     log_containerId,
+		ContainerName:   log_containerName,
+		Tty:             req.Tty,
+		SeccompProfile:  log_seccompProfile,
+		AppArmorProfile: log_appArmorProfile,
 	})
 
 	// service: start
@@ -123,9 +180,10 @@ func (h *RequestHandler) StartContainer(w http.ResponseWriter, r *http.Request)
 
 // StopContainer godoc
 // @Summary stop a container
-// @Description stop an exitsting container
+// @Description stop an exitsting container. the stop signal is sent first and SIGKILL after the timeout
 // @Tags containers
 // @Param containerId path string true "Container ID"
+// @Param request body StopContainerRequest false "Stop Options"
 // @Success 201 {object} apimodel.ApiResponse
 // @Router /v1/containers/{containerId}/actions/stop [post]
 func (h *RequestHandler) StopContainer(w http.ResponseWriter, r *http.Request) {
@@ -135,6 +193,14 @@ func (h *RequestHandler) StopContainer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// decode request
+	//   empty body uses image stop signal and default timeout
+	var req StopContainerRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), StopContainerResponse{Id: containerId})
+		return
+	}
+
 	// set log: target
 	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
 	logger.SetTarget(r.Context(), logger.Target{
@@ -146,6 +212,8 @@ func (h *RequestHandler) StopContainer(w http.ResponseWriter, r *http.Request) {
 	result, err := h.serviceHandler.Stop(
 		container.ServiceStopModel{
 			ContainerId: containerId,
+			Timeout:     req.Timeout,
+			Signal:      req.Signal,
 		},
 	)
 	if err != nil {
@@ -157,6 +225,231 @@ func (h *RequestHandler) StopContainer(w http.ResponseWriter, r *http.Request) {
 	apimodel.RespondSuccess(w, http.StatusOK, "container stopped", StopContainerResponse{Id: result})
 }
 
+// KillContainer godoc
+// @Summary kill a container
+// @Description send a signal to the init process of a container
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Param request body KillContainerRequest false "Kill Options"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/kill [post]
+func (h *RequestHandler) KillContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", KillContainerResponse{Id: ""})
+		return
+	}
+
+	// decode request
+	var req KillContainerRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), KillContainerResponse{Id: containerId})
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+	logger.PutExtra(r.Context(), "signal", req.Signal)
+
+	// service: kill
+	result, err := h.serviceHandler.Kill(
+		container.ServiceKillModel{
+			ContainerId: containerId,
+			Signal:      req.Signal,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), KillContainerResponse{Id: containerId})
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "signal sent", KillContainerResponse{Id: result})
+}
+
+// WaitContainer godoc
+// @Summary wait a container
+// @Description block until the container reaches the condition and return the exit code
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Param request body WaitContainerRequest false "Wait Options"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/wait [post]
+func (h *RequestHandler) WaitContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", WaitContainerResponse{Id: ""})
+		return
+	}
+
+	// decode request
+	var req WaitContainerRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), WaitContainerResponse{Id: containerId})
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+
+	// service: wait
+	//   request context is canceled when the client disconnects
+	result, err := h.serviceHandler.Wait(
+		r.Context(),
+		container.ServiceWaitModel{
+			ContainerId: containerId,
+			Condition:   req.Condition,
+			Timeout:     req.Timeout,
+		},
+	)
+	if err != nil {
+		if r.Context().Err() != nil {
+			// client disconnected
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			apimodel.RespondFail(w, http.StatusRequestTimeout, "service failed: "+err.Error(), WaitContainerResponse{Id: containerId})
+			return
+		}
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), WaitContainerResponse{Id: containerId})
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "container "+result.State, WaitContainerResponse{
+		Id:             result.ContainerId,
+		ExitCode:       result.ExitCode,
+		FinishedReason: result.FinishedReason,
+		OomKilled:      result.OomKilled,
+	})
+}
+
+// PauseContainer godoc
+// @Summary pause a container
+// @Description freeze all processes of a running container via cgroup.freeze
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/pause [post]
+func (h *RequestHandler) PauseContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", PauseContainerResponse{Id: ""})
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+
+	// service: pause
+	result, err := h.serviceHandler.Pause(
+		container.ServicePauseModel{
+			ContainerId: containerId,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), PauseContainerResponse{Id: containerId})
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "container paused", PauseContainerResponse{Id: result})
+}
+
+// UnpauseContainer godoc
+// @Summary unpause a container
+// @Description thaw all processes of a paused container
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/unpause [post]
+func (h *RequestHandler) UnpauseContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", UnpauseContainerResponse{Id: ""})
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+
+	// service: unpause
+	result, err := h.serviceHandler.Unpause(
+		container.ServiceUnpauseModel{
+			ContainerId: containerId,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), UnpauseContainerResponse{Id: containerId})
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "container unpaused", UnpauseContainerResponse{Id: result})
+}
+
+// UpdateContainer godoc
+// @Summary update a container
+// @Description update resource limits of a running container without restart
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Param request body UpdateContainerRequest true "Resource Limits"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/update [post]
+func (h *RequestHandler) UpdateContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", UpdateContainerResponse{Id: ""})
+		return
+	}
+
+	// decode request
+	var req UpdateContainerRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), UpdateContainerResponse{Id: containerId})
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+	logger.PutExtra(r.Context(), "resources", req.Resources)
+
+	// service: update
+	result, err := h.serviceHandler.Update(
+		container.ServiceUpdateModel{
+			ContainerId: containerId,
+			Resources:   toResourceModel(req.Resources),
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), UpdateContainerResponse{Id: containerId})
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "container updated", UpdateContainerResponse{Id: result})
+}
+
 // ExecContainer godoc
 // @Summary exec a container
 // @Description execute command inside an exitsting container
@@ -240,13 +533,42 @@ func (h *RequestHandler) DeleteContainer(w http.ResponseWriter, r *http.Request)
 
 // GetContainerList godoc
 // @Summary get container list
-// @Description get all container list
+// @Description get container list. filters are combined with AND
 // @Tags containers
+// @Param label query []string false "Label filter (key or key=value)" collectionFormat(multi)
+// @Param state query []string false "State filter" collectionFormat(multi)
+// @Param image query string false "Image filter (repository[:reference])"
+// @Param name query string false "Name prefix"
+// @Param since query string false "Created at or after (RFC3339)"
+// @Param before query string false "Created before (RFC3339)"
 // @Success 200 {object} apimodel.ApiResponse
 // @Router /v1/containers [get]
 func (h *RequestHandler) GetContainerList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	listParameter := container.ServiceListModel{
+		Labels:     query["label"],
+		Image:      query.Get("image"),
+		NamePrefix: query.Get("name"),
+	}
+	//    state accepts both repeated and comma separated values
+	for _, v := range query["state"] {
+		listParameter.States = append(listParameter.States, strings.Split(v, ",")...)
+	}
+	for key, dst := range map[string]*time.Time{"since": &listParameter.CreatedSince, "before": &listParameter.CreatedBefore} {
+		v := query.Get(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apimodel.RespondFail(w, http.StatusBadRequest, "invalid "+key+": "+v, nil)
+			return
+		}
+		*dst = t
+	}
+
 	// service: get container list
-	containerList, err := h.serviceHandler.GetContainerList()
+	containerList, err := h.serviceHandler.GetContainerList(listParameter)
 	if err != nil {
 		apimodel.RespondFail(w, http.StatusInternalServerError, "retrieve container list failed: "+err.Error(), nil)
 		return
@@ -319,3 +641,415 @@ func (h *RequestHandler) GetContainerLog(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// GetContainerStats godoc
+// @Summary get container stats
+// @Description get resource usage of a container from cgroup. stream=true writes a json line per second until the client disconnects
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Param stream query bool false "Stream stats"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/stats [get]
+func (h *RequestHandler) GetContainerStats(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing container Id", nil)
+		return
+	}
+	stream := false
+	if v := r.URL.Query().Get("stream"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			apimodel.RespondFail(w, http.StatusBadRequest, "invalid stream", nil)
+			return
+		}
+		stream = b
+	}
+
+	// service: get stats
+	//   cpu percent needs two samples
+	previous, err := h.serviceHandler.GetContainerStats(containerId, container.ContainerStats{})
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "retrieve stats failed: "+err.Error(), nil)
+		return
+	}
+
+	if !stream {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(statsInterval):
+		}
+		stats, err := h.serviceHandler.GetContainerStats(containerId, previous)
+		if err != nil {
+			apimodel.RespondFail(w, http.StatusInternalServerError, "retrieve stats failed: "+err.Error(), nil)
+			return
+		}
+		apimodel.RespondSuccess(w, http.StatusOK, "retrieve stats success", stats)
+		return
+	}
+
+	// stream: json lines
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "streaming not supported", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		stats, err := h.serviceHandler.GetContainerStats(containerId, previous)
+		if err != nil {
+			// container stopped or removed
+			return
+		}
+		if err := enc.Encode(stats); err != nil {
+			return
+		}
+		flusher.Flush()
+		previous = stats
+	}
+}
+
+// RenameContainer godoc
+// @Summary rename a container
+// @Description change the name of a container. network policies referring to the old name are updated
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Param request body RenameContainerRequest true "New Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/rename [post]
+func (h *RequestHandler) RenameContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", RenameContainerResponse{Id: ""})
+		return
+	}
+
+	// decode request
+	var req RenameContainerRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), RenameContainerResponse{Id: containerId})
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+	logger.PutExtra(r.Context(), "newName", req.Name)
+
+	// service: rename
+	result, err := h.serviceHandler.Rename(
+		container.ServiceRenameModel{
+			ContainerId: containerId,
+			Name:        req.Name,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), RenameContainerResponse{Id: containerId})
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "container renamed", RenameContainerResponse{Id: result})
+}
+
+// CommitContainer godoc
+// @Summary commit a container
+// @Description create a new image from the container changes
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Param request body CommitContainerRequest true "Commit Options"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/actions/commit [post]
+func (h *RequestHandler) CommitContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing containerId", nil)
+		return
+	}
+
+	// decode request
+	var req CommitContainerRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+	if req.Image == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing image", nil)
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+	logger.PutExtra(r.Context(), "image", req.Image)
+
+	// service: commit
+	result, err := h.serviceHandler.Commit(
+		container.ServiceCommitModel{
+			ContainerId: containerId,
+			Image:       req.Image,
+			Cmd:         req.Cmd,
+			Env:         req.Env,
+			Comment:     req.Comment,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "service failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "container committed", result)
+}
+
+// GetContainerTop godoc
+// @Summary get container processes
+// @Description list processes running in a container
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/top [get]
+func (h *RequestHandler) GetContainerTop(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing container Id", nil)
+		return
+	}
+
+	// service: get top
+	top, err := h.serviceHandler.GetContainerTop(containerId)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "retrieve processes failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve processes success", top)
+}
+
+// GetContainerChanges godoc
+// @Summary get container filesystem changes
+// @Description list files added, modified or deleted in a container against its image
+// @Tags containers
+// @Param containerId path string true "Container ID"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/changes [get]
+func (h *RequestHandler) GetContainerChanges(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing container Id", nil)
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+
+	// service: get changes
+	changes, err := h.serviceHandler.GetContainerChanges(containerId)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "retrieve changes failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve changes success", changes)
+}
+
+// GetContainerArchive godoc
+// @Summary get files from a container
+// @Description stream the file or directory at path in the container rootfs as a tar archive
+// @Tags containers
+// @Produce application/x-tar
+// @Param containerId path string true "Container ID"
+// @Param path query string true "Path in the container"
+// @Success 200 {file} file
+// @Router /v1/containers/{containerId}/archive [get]
+func (h *RequestHandler) GetContainerArchive(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing container Id", nil)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing path", nil)
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+	logger.PutExtra(r.Context(), "path", path)
+
+	// service: get archive
+	archive, err := h.serviceHandler.GetArchive(
+		container.ServiceArchiveModel{
+			ContainerId: containerId,
+			Path:        path,
+		},
+	)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			apimodel.RespondFail(w, http.StatusNotFound, "get archive failed: "+err.Error(), nil)
+			return
+		}
+		apimodel.RespondFail(w, http.StatusInternalServerError, "get archive failed: "+err.Error(), nil)
+		return
+	}
+	defer archive.Close()
+
+	// stream tar
+	w.Header().Set("Content-Type", "application/x-tar")
+	_, _ = io.Copy(w, archive)
+}
+
+// PutContainerArchive godoc
+// @Summary put files into a container
+// @Description extract a tar archive (optionally gzip compressed) into the directory at path in the container rootfs
+// @Tags containers
+// @Accept application/x-tar
+// @Param containerId path string true "Container ID"
+// @Param path query string true "Destination directory in the container"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/containers/{containerId}/archive [put]
+func (h *RequestHandler) PutContainerArchive(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing container Id", nil)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing path", nil)
+		return
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+	logger.PutExtra(r.Context(), "path", path)
+
+	// service: put archive
+	if err := h.serviceHandler.PutArchive(
+		container.ServiceArchiveModel{
+			ContainerId: containerId,
+			Path:        path,
+		},
+		r.Body,
+	); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			apimodel.RespondFail(w, http.StatusNotFound, "put archive failed: "+err.Error(), nil)
+			return
+		}
+		apimodel.RespondFail(w, http.StatusInternalServerError, "put archive failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "archive extracted", nil)
+}
+
+// ExportContainer godoc
+// @Summary export a container
+// @Description stream the merged rootfs of a container as a tar archive. gzip=true compresses it
+// @Tags containers
+// @Produce application/x-tar
+// @Param containerId path string true "Container ID"
+// @Param gzip query bool false "Gzip compression"
+// @Success 200 {file} file
+// @Router /v1/containers/{containerId}/export [get]
+func (h *RequestHandler) ExportContainer(w http.ResponseWriter, r *http.Request) {
+	containerId := chi.URLParam(r, "containerId")
+	if containerId == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing container Id", nil)
+		return
+	}
+	compress := false
+	if v := r.URL.Query().Get("gzip"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			apimodel.RespondFail(w, http.StatusBadRequest, "invalid gzip", nil)
+			return
+		}
+		compress = b
+	}
+
+	// set log: target
+	log_containerId, log_containerName, _ := h.csmHandler.GetContainerIdAndName(containerId)
+	logger.SetTarget(r.Context(), logger.Target{
+		ContainerId:   log_containerId,
+		ContainerName: log_containerName,
+	})
+
+	// service: export
+	archive, err := h.serviceHandler.Export(
+		container.ServiceExportModel{
+			ContainerId: containerId,
+			Gzip:        compress,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "export failed: "+err.Error(), nil)
+		return
+	}
+	defer archive.Close()
+
+	// stream tar
+	if compress {
+		w.Header().Set("Content-Type", "application/gzip")
+	} else {
+		w.Header().Set("Content-Type", "application/x-tar")
+	}
+	_, _ = io.Copy(w, archive)
+}
+
+func toResourceModel(req ResourceRequest) container.ResourceModel {
+	return container.ResourceModel{
+		MemoryMax:     req.MemoryMax,
+		MemorySwapMax: req.MemorySwapMax,
+		CpuMax:        req.CpuMax,
+		CpuWeight:     req.CpuWeight,
+		PidsMax:       req.PidsMax,
+		IoMax:         req.IoMax,
+	}
+}
+
+func toTmpfsModel(req []TmpfsRequest) []container.TmpfsModel {
+	var tmpfs []container.TmpfsModel
+	for _, t := range req {
+		tmpfs = append(tmpfs, container.TmpfsModel{
+			Target: t.Target,
+			Size:   t.Size,
+			Mode:   t.Mode,
+			Noexec: t.Noexec,
+		})
+	}
+	return tmpfs
+}
@@ -10,6 +10,69 @@ type CreateContainerRequest struct {
 	Network string   `json:"network" example:"raind0"`
 	Tty     bool     `json:"tty" example:"false"`
 	Name    string   `json:"name"  example:"my-container"`
+
+	// overrides of the image config. when entrypoint is set, command is passed as its arguments
+	Entrypoint []string `json:"entrypoint,omitempty" example:"/docker-entrypoint.sh"`
+	User       string   `json:"user,omitempty" example:"nobody"`
+	WorkingDir string   `json:"workingDir,omitempty" example:"/app"`
+
+	// hostname defaults to the container id. dns servers default to the host resolv.conf
+	Hostname   string   `json:"hostname,omitempty" example:"web"`
+	Domainname string   `json:"domainname,omitempty" example:"example.internal"`
+	Dns        []string `json:"dns,omitempty" example:"1.1.1.1,8.8.8.8"`
+	DnsSearch  []string `json:"dnsSearch,omitempty" example:"example.internal"`
+	DnsOptions []string `json:"dnsOptions,omitempty" example:"ndots:2,timeout:1"`
+	ExtraHosts []string `json:"extraHosts,omitempty" example:"db:10.0.0.5"`
+
+	// mounts the rootfs read-only. /tmp and /run get tmpfs unless mounted by the user
+	ReadOnlyRootfs bool           `json:"readOnlyRootfs,omitempty" example:"false"`
+	Tmpfs          []TmpfsRequest `json:"tmpfs,omitempty"`
+
+	// applied to the default capability set. "ALL" is accepted
+	CapAdd          []string `json:"capAdd,omitempty" example:"NET_ADMIN"`
+	CapDrop         []string `json:"capDrop,omitempty" example:"MKNOD,NET_RAW"`
+	NoNewPrivileges bool     `json:"noNewPrivileges,omitempty" example:"true"`
+	// registered seccomp profile. empty uses raind-default, "unconfined" disables seccomp
+	SeccompProfile string `json:"seccompProfile,omitempty" example:"raind-default"`
+	// loaded apparmor profile. empty uses raind-default, "unconfined" disables apparmor
+	AppArmorProfile string `json:"appArmorProfile,omitempty" example:"raind-default"`
+
+	Resources     ResourceRequest      `json:"resources"`
+	RestartPolicy RestartPolicyRequest `json:"restartPolicy"`
+	Healthcheck   HealthcheckRequest   `json:"healthcheck"`
+	AutoRemove    bool                 `json:"autoRemove" example:"false"`
+	Labels        map[string]string    `json:"labels,omitempty"`
+}
+
+// overrides the image healthcheck. test=["NONE"] disables it
+type HealthcheckRequest struct {
+	Test         []string `json:"test,omitempty" example:"CMD-SHELL,wget -q -O /dev/null http://localhost/ || exit 1"`
+	Interval     string   `json:"interval,omitempty" example:"30s"`
+	Timeout      string   `json:"timeout,omitempty" example:"5s"`
+	StartPeriod  string   `json:"startPeriod,omitempty" example:"10s"`
+	Retries      int      `json:"retries,omitempty" example:"3"`
+	RestartAfter int      `json:"restartAfter,omitempty" example:"5"`
+}
+
+type TmpfsRequest struct {
+	Target string `json:"target" example:"/cache"`
+	Size   string `json:"size,omitempty" example:"64m"`
+	Mode   string `json:"mode,omitempty" example:"1777"`
+	Noexec bool   `json:"noexec,omitempty" example:"true"`
+}
+
+type RestartPolicyRequest struct {
+	Name       string `json:"name" example:"on-failure"`
+	MaxRetries int    `json:"maxRetries,omitempty" example:"3"`
+}
+
+type ResourceRequest struct {
+	MemoryMax     string   `json:"memoryMax,omitempty" example:"512M"`
+	MemorySwapMax string   `json:"memorySwapMax,omitempty" example:"0"`
+	CpuMax        string   `json:"cpuMax,omitempty" example:"50000 100000"`
+	CpuWeight     int      `json:"cpuWeight,omitempty" example:"100"`
+	PidsMax       string   `json:"pidsMax,omitempty" example:"256"`
+	IoMax         []string `json:"ioMax,omitempty" example:"8:0 rbps=1048576 wbps=1048576"`
 }
 
 type CreateContainerResponse struct {
@@ -26,10 +89,77 @@ type StartContainerResponse struct {
 }
 
 // == stop ==
+// body is optional
+type StopContainerRequest struct {
+	Timeout *int   `json:"timeout,omitempty" example:"10"`
+	Signal  string `json:"signal,omitempty" example:"SIGTERM"`
+}
+
 type StopContainerResponse struct {
 	Id string `json:"id"`
 }
 
+// == kill ==
+// body is optional. default signal is SIGKILL
+type KillContainerRequest struct {
+	Signal string `json:"signal,omitempty" example:"SIGHUP"`
+}
+
+type KillContainerResponse struct {
+	Id string `json:"id"`
+}
+
+// == wait ==
+// body is optional. condition: stopped (default), not-running or removed
+type WaitContainerRequest struct {
+	Condition string `json:"condition,omitempty" example:"stopped"`
+	Timeout   int    `json:"timeout,omitempty" example:"60"`
+}
+
+type WaitContainerResponse struct {
+	Id             string `json:"id"`
+	ExitCode       *int   `json:"exitCode"`
+	FinishedReason string `json:"finishedReason"`
+	OomKilled      bool   `json:"oomKilled"`
+}
+
+// == pause ==
+type PauseContainerResponse struct {
+	Id string `json:"id"`
+}
+
+// == unpause ==
+type UnpauseContainerResponse struct {
+	Id string `json:"id"`
+}
+
+// == update ==
+type UpdateContainerRequest struct {
+	Resources ResourceRequest `json:"resources"`
+}
+
+type UpdateContainerResponse struct {
+	Id string `json:"id"`
+}
+
+// == commit ==
+// cmd replaces the image Cmd, env is merged into the image Env by key
+type CommitContainerRequest struct {
+	Image   string   `json:"image" example:"myapp:v1"`
+	Cmd     []string `json:"cmd,omitempty" example:"/bin/sh,-c,echo hello"`
+	Env     []string `json:"env,omitempty" example:"MODE=production"`
+	Comment string   `json:"comment,omitempty" example:"add config"`
+}
+
+// == rename ==
+type RenameContainerRequest struct {
+	Name string `json:"name" example:"new-name"`
+}
+
+type RenameContainerResponse struct {
+	Id string `json:"id"`
+}
+
 // == exec ==
 type ExecContainerRequest struct {
 	Command []string `json:"command" example:"/bin/sh,-c,echo hello"`
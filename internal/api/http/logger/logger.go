@@ -106,6 +106,21 @@ func LoggerMiddleware(l Logger, component string, node string) func(http.Handler
 	}
 }
 
+// NewEvent builds an event for operations not triggered by api request (e.g. container monitor)
+func NewEvent(action string, component string, node string) Event {
+	return Event{
+		TS:       time.Now().Format(time.RFC3339Nano),
+		EventId:  uuid.NewString(),
+		Severity: Severity[severityForAction(action)],
+		Action:   action,
+		Runtime: Runtime{
+			Component: component,
+			Node:      node,
+		},
+		Extra: map[string]any{},
+	}
+}
+
 func FromContext(ctx context.Context) *Event {
 	ev, _ := ctx.Value(ctxEventKey).(*Event)
 	return ev
@@ -150,6 +165,12 @@ func SetTarget(ctx context.Context, target Target) {
 		if len(target.Mount) != 0 {
 			ev.Target.Mount = target.Mount
 		}
+		if target.SeccompProfile != "" {
+			ev.Target.SeccompProfile = target.SeccompProfile
+		}
+		if target.AppArmorProfile != "" {
+			ev.Target.AppArmorProfile = target.AppArmorProfile
+		}
 
 		// policy
 		if target.PolicyId != "" {
@@ -41,6 +41,9 @@ type Target struct {
 	Mount         []string `json:"mount,omitempty"`
 	Network       string   `json:"network,omitempty"`
 	Tty           bool     `json:"tty,omitempty"`
+	// security profiles the container runs under
+	SeccompProfile  string `json:"seccomp_profile,omitempty"`
+	AppArmorProfile string `json:"apparmor_profile,omitempty"`
 
 	// policy
 	PolicyId    string `json:"policy_id,omitempty"`
@@ -104,12 +107,37 @@ var rules = []Rule{
 	// container
 	{"GET", "/v1/containers", "container.list", SEV_INFO},
 	{"GET", "/v1/containers/{containerId}", "container.info", SEV_INFO},
+	{"GET", "/v1/containers/{containerId}/stats", "container.stats", SEV_INFO},
+	{"GET", "/v1/containers/{containerId}/top", "container.top", SEV_INFO},
+	{"GET", "/v1/containers/{containerId}/changes", "container.changes", SEV_INFO},
+	{"GET", "/v1/containers/{containerId}/archive", "container.archive.get", SEV_MEDIUM},
+	{"PUT", "/v1/containers/{containerId}/archive", "container.archive.put", SEV_HIGH},
+	{"GET", "/v1/containers/{containerId}/export", "container.export", SEV_MEDIUM},
 	{"POST", "/v1/containers", "container.create", SEV_MEDIUM},
 	{"POST", "/v1/containers/{containerId}/actions/start", "container.start", SEV_MEDIUM},
 	{"POST", "/v1/containers/{containerId}/actions/stop", "container.stop", SEV_MEDIUM},
+	{"POST", "/v1/containers/{containerId}/actions/kill", "container.kill", SEV_HIGH},
+	{"POST", "/v1/containers/{containerId}/actions/wait", "container.wait", SEV_INFO},
+	{"POST", "/v1/containers/{containerId}/actions/pause", "container.pause", SEV_MEDIUM},
+	{"POST", "/v1/containers/{containerId}/actions/unpause", "container.unpause", SEV_MEDIUM},
+	{"POST", "/v1/containers/{containerId}/actions/update", "container.update", SEV_MEDIUM},
 	{"POST", "/v1/containers/{containerId}/actions/exec", "container.exec", SEV_HIGH},
+	{"POST", "/v1/containers/{containerId}/actions/commit", "container.commit", SEV_MEDIUM},
+	{"POST", "/v1/containers/{containerId}/actions/rename", "container.rename", SEV_MEDIUM},
 	{"DELETE", "/v1/containers/{containerId}/actions/delete", "container.delete", SEV_HIGH},
 
+	// security
+	{"GET", "/v1/security/seccomp", "security.seccomp.list", SEV_INFO},
+	{"GET", "/v1/security/seccomp/{profileName}", "security.seccomp.info", SEV_INFO},
+	{"POST", "/v1/security/seccomp", "security.seccomp.add", SEV_HIGH},
+	{"DELETE", "/v1/security/seccomp/{profileName}", "security.seccomp.remove", SEV_HIGH},
+	{"GET", "/v1/security/apparmor", "security.apparmor.list", SEV_INFO},
+	{"GET", "/v1/security/apparmor/{profileName}", "security.apparmor.info", SEV_INFO},
+	{"POST", "/v1/security/apparmor", "security.apparmor.add", SEV_HIGH},
+	{"POST", "/v1/security/apparmor/validate", "security.apparmor.validate", SEV_INFO},
+	{"POST", "/v1/security/apparmor/{profileName}/mode", "security.apparmor.mode", SEV_HIGH},
+	{"DELETE", "/v1/security/apparmor/{profileName}", "security.apparmor.remove", SEV_HIGH},
+
 	// websocket
 	{"GET", "/v1/containers/{containerId}/attach", "ws.attach", SEV_HIGH},
 	{"GET", "/v1/containers/{containerId}/exec/attach", "ws.exec.attach", SEV_HIGH},
@@ -122,6 +150,13 @@ var rules = []Rule{
 	{"POST", "/v1/images", "image.pull", SEV_MEDIUM},
 	{"DELETE", "/v1/images", "image.remove", SEV_HIGH},
 
+	// volume
+	{"GET", "/v1/volumes", "volume.list", SEV_INFO},
+	{"GET", "/v1/volumes/{volumeName}", "volume.info", SEV_INFO},
+	{"POST", "/v1/volumes", "volume.create", SEV_MEDIUM},
+	{"POST", "/v1/volumes/prune", "volume.prune", SEV_HIGH},
+	{"DELETE", "/v1/volumes/{volumeName}", "volume.remove", SEV_HIGH},
+
 	// policy
 	{"GET", "/v1/policies/{chain}", "policy.list", SEV_INFO},
 	{"POST", "/v1/policies", "policy.add", SEV_MEDIUM},
@@ -130,6 +165,9 @@ var rules = []Rule{
 	{"POST", "/v1/policies/ns/mode", "policy.mode.change", SEV_CRITICAL},
 	{"DELETE", "/v1/policies/{policyId}", "policy.delete", SEV_MEDIUM},
 
+	// system
+	{"POST", "/v1/system/prune", "system.prune", SEV_HIGH},
+
 	// pki
 	{"POST", "/v1/pki/sign", "pki.sign", SEV_HIGH},
 }
@@ -140,4 +178,5 @@ var actionSeverity = map[string]int{
 	"hook.poststart":       SEV_MEDIUM,
 	"hook.stopContainer":   SEV_MEDIUM,
 	"hook.poststop":        SEV_MEDIUM,
+	"monitor.autoRemove":   SEV_MEDIUM,
 }
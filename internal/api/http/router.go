@@ -14,6 +14,9 @@ import (
 	"condenser/internal/api/http/logger"
 	logHandler "condenser/internal/api/http/logs"
 	policyHandler "condenser/internal/api/http/policy"
+	securityHandler "condenser/internal/api/http/security"
+	systemHandler "condenser/internal/api/http/system"
+	volumeHandler "condenser/internal/api/http/volume"
 	websocketHandler "condenser/internal/api/http/websocket"
 	"condenser/internal/utils"
 
@@ -51,6 +54,9 @@ func NewApiRouter() *chi.Mux {
 	execSocketHandler := websocketHandler.NewExecRequestHandler()
 	policyHandler := policyHandler.NewRequestHandler()
 	logHandler := logHandler.NewRequestHandler()
+	systemHandler := systemHandler.NewRequestHandler()
+	volumeHandler := volumeHandler.NewRequestHandler()
+	securityHandler := securityHandler.NewRequestHandler()
 
 	// middleware
 	r.Use(middleware.RequestID)
@@ -71,10 +77,23 @@ func NewApiRouter() *chi.Mux {
 	r.Get("/v1/containers", containerHandler.GetContainerList)                                // get container list
 	r.Get("/v1/containers/{containerId}", containerHandler.GetContainerById)                  // get container status by id
 	r.Get("/v1/containers/{containerId}/log", containerHandler.GetContainerLog)               // get container log
+	r.Get("/v1/containers/{containerId}/stats", containerHandler.GetContainerStats)           // get container stats
+	r.Get("/v1/containers/{containerId}/top", containerHandler.GetContainerTop)               // get container processes
+	r.Get("/v1/containers/{containerId}/changes", containerHandler.GetContainerChanges)       // get container filesystem changes
+	r.Get("/v1/containers/{containerId}/archive", containerHandler.GetContainerArchive)       // get files from container
+	r.Put("/v1/containers/{containerId}/archive", containerHandler.PutContainerArchive)       // put files into container
+	r.Get("/v1/containers/{containerId}/export", containerHandler.ExportContainer)            // export container rootfs
 	r.Post("/v1/containers", containerHandler.CreateContainer)                                // create container
 	r.Post("/v1/containers/{containerId}/actions/start", containerHandler.StartContainer)     // start container
 	r.Post("/v1/containers/{containerId}/actions/stop", containerHandler.StopContainer)       // stop container
+	r.Post("/v1/containers/{containerId}/actions/kill", containerHandler.KillContainer)       // kill container
+	r.Post("/v1/containers/{containerId}/actions/wait", containerHandler.WaitContainer)       // wait container
+	r.Post("/v1/containers/{containerId}/actions/pause", containerHandler.PauseContainer)     // pause container
+	r.Post("/v1/containers/{containerId}/actions/unpause", containerHandler.UnpauseContainer) // unpause container
+	r.Post("/v1/containers/{containerId}/actions/update", containerHandler.UpdateContainer)   // update container
 	r.Post("/v1/containers/{containerId}/actions/exec", containerHandler.ExecContainer)       // exec container
+	r.Post("/v1/containers/{containerId}/actions/commit", containerHandler.CommitContainer)   // commit container
+	r.Post("/v1/containers/{containerId}/actions/rename", containerHandler.RenameContainer)   // rename container
 	r.Delete("/v1/containers/{containerId}/actions/delete", containerHandler.DeleteContainer) // delete container
 
 	// == images ==
@@ -82,6 +101,25 @@ func NewApiRouter() *chi.Mux {
 	r.Post("/v1/images", imageHandler.PullImage)     // pull image
 	r.Delete("/v1/images", imageHandler.RemoveImage) // remove image
 
+	// == volumes ==
+	r.Get("/v1/volumes", volumeHandler.GetVolumeList)                // get volume list
+	r.Get("/v1/volumes/{volumeName}", volumeHandler.GetVolumeByName) // get volume info
+	r.Post("/v1/volumes", volumeHandler.CreateVolume)                // create volume
+	r.Post("/v1/volumes/prune", volumeHandler.PruneVolumes)          // prune unused volumes
+	r.Delete("/v1/volumes/{volumeName}", volumeHandler.RemoveVolume) // remove volume
+
+	// == security ==
+	r.Get("/v1/security/seccomp", securityHandler.GetSeccompProfileList)                       // get seccomp profile list
+	r.Get("/v1/security/seccomp/{profileName}", securityHandler.GetSeccompProfile)             // get seccomp profile
+	r.Post("/v1/security/seccomp", securityHandler.AddSeccompProfile)                          // add seccomp profile
+	r.Delete("/v1/security/seccomp/{profileName}", securityHandler.RemoveSeccompProfile)       // remove seccomp profile
+	r.Get("/v1/security/apparmor", securityHandler.GetAppArmorProfileList)                     // get apparmor profile list
+	r.Get("/v1/security/apparmor/{profileName}", securityHandler.GetAppArmorProfile)           // get apparmor profile
+	r.Post("/v1/security/apparmor", securityHandler.AddAppArmorProfile)                        // add and load apparmor profile
+	r.Post("/v1/security/apparmor/validate", securityHandler.ValidateAppArmorProfile)          // validate apparmor profile
+	r.Post("/v1/security/apparmor/{profileName}/mode", securityHandler.SetAppArmorProfileMode) // switch enforce/complain
+	r.Delete("/v1/security/apparmor/{profileName}", securityHandler.RemoveAppArmorProfile)     // remove apparmor profile
+
 	// == websocket ==
 	r.Get("/v1/containers/{containerId}/attach", socketHandler.ServeHTTP)
 	r.Get("/v1/containers/{containerId}/exec/attach", execSocketHandler.ServeHTTP)
@@ -97,6 +135,9 @@ func NewApiRouter() *chi.Mux {
 	// == logs ==
 	r.Get("/v1/logs/netflow", logHandler.GetNetflowLog) // get netflow log
 
+	// == system ==
+	r.Post("/v1/system/prune", systemHandler.Prune) // prune unused resources
+
 	return r
 }
 
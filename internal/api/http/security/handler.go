@@ -0,0 +1,308 @@
+package security
+
+import (
+	"condenser/internal/core/security"
+	"net/http"
+
+	"condenser/internal/api/http/logger"
+	apimodel "condenser/internal/api/http/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewRequestHandler() *RequestHandler {
+	return &RequestHandler{
+		serviceHandler: security.NewSecurityService(),
+	}
+}
+
+type RequestHandler struct {
+	serviceHandler security.SecurityServiceHandler
+}
+
+// AddSeccompProfile godoc
+// @Summary add seccomp profile
+// @Description register a seccomp profile in the OCI format. unknown syscall names are rejected
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param request body AddSeccompProfileRequest true "Profile Name and Body"
+// @Success 201 {object} apimodel.ApiResponse
+// @Router /v1/security/seccomp [post]
+func (h *RequestHandler) AddSeccompProfile(w http.ResponseWriter, r *http.Request) {
+	// decode request
+	var req AddSeccompProfileRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+	logger.PutExtra(r.Context(), "seccompProfile", req.Name)
+
+	// service
+	if err := h.serviceHandler.AddSeccompProfile(
+		security.ServiceSeccompModel{
+			Name:    req.Name,
+			Profile: req.Profile,
+		},
+	); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "add failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusCreated, "seccomp profile added", map[string]string{"name": req.Name})
+}
+
+// GetSeccompProfileList godoc
+// @Summary get seccomp profile list
+// @Description get registered seccomp profiles with the containers using them
+// @Tags security
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/seccomp [get]
+func (h *RequestHandler) GetSeccompProfileList(w http.ResponseWriter, r *http.Request) {
+	// service
+	profileList, err := h.serviceHandler.GetSeccompProfileList()
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve seccomp profile list success", profileList)
+}
+
+// GetSeccompProfile godoc
+// @Summary get seccomp profile
+// @Description get a registered seccomp profile
+// @Tags security
+// @Param profileName path string true "Profile Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/seccomp/{profileName} [get]
+func (h *RequestHandler) GetSeccompProfile(w http.ResponseWriter, r *http.Request) {
+	profileName := chi.URLParam(r, "profileName")
+	if profileName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing profile name", nil)
+		return
+	}
+
+	// service
+	profile, err := h.serviceHandler.GetSeccompProfile(profileName)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve seccomp profile success", profile)
+}
+
+// RemoveSeccompProfile godoc
+// @Summary remove seccomp profile
+// @Description remove a registered seccomp profile. the built-in profile and profiles in use are refused
+// @Tags security
+// @Param profileName path string true "Profile Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/seccomp/{profileName} [delete]
+func (h *RequestHandler) RemoveSeccompProfile(w http.ResponseWriter, r *http.Request) {
+	profileName := chi.URLParam(r, "profileName")
+	if profileName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing profile name", nil)
+		return
+	}
+	logger.PutExtra(r.Context(), "seccompProfile", profileName)
+
+	// service
+	if err := h.serviceHandler.RemoveSeccompProfile(profileName); err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "remove failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "seccomp profile removed", map[string]string{"name": profileName})
+}
+
+// AddAppArmorProfile godoc
+// @Summary add apparmor profile
+// @Description validate a profile with apparmor_parser, load it into the kernel and store it. the name must start with "raind-". existing profiles are replaced
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param request body AddAppArmorProfileRequest true "Profile Name and Body"
+// @Success 201 {object} apimodel.ApiResponse
+// @Router /v1/security/apparmor [post]
+func (h *RequestHandler) AddAppArmorProfile(w http.ResponseWriter, r *http.Request) {
+	// decode request
+	var req AddAppArmorProfileRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+
+	// set log: target
+	logger.SetTarget(r.Context(), logger.Target{
+		AppArmorProfile: req.Name,
+	})
+
+	// service
+	if err := h.serviceHandler.AddAppArmorProfile(
+		security.ServiceAppArmorModel{
+			Name:    req.Name,
+			Profile: []byte(req.Profile),
+		},
+	); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "add failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusCreated, "apparmor profile added", map[string]string{"name": req.Name})
+}
+
+// ValidateAppArmorProfile godoc
+// @Summary validate apparmor profile
+// @Description check a profile with apparmor_parser without loading or storing it
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param request body AddAppArmorProfileRequest true "Profile Name and Body"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/apparmor/validate [post]
+func (h *RequestHandler) ValidateAppArmorProfile(w http.ResponseWriter, r *http.Request) {
+	// decode request
+	var req AddAppArmorProfileRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+
+	// service
+	if err := h.serviceHandler.ValidateAppArmorProfile(
+		security.ServiceAppArmorModel{
+			Name:    req.Name,
+			Profile: []byte(req.Profile),
+		},
+	); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "validation failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "apparmor profile is valid", map[string]string{"name": req.Name})
+}
+
+// GetAppArmorProfileList godoc
+// @Summary get apparmor profile list
+// @Description get stored apparmor profiles with the kernel mode and the containers using them
+// @Tags security
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/apparmor [get]
+func (h *RequestHandler) GetAppArmorProfileList(w http.ResponseWriter, r *http.Request) {
+	// service
+	profileList, err := h.serviceHandler.GetAppArmorProfileList()
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve apparmor profile list success", profileList)
+}
+
+// GetAppArmorProfile godoc
+// @Summary get apparmor profile
+// @Description get a stored apparmor profile and its source
+// @Tags security
+// @Param profileName path string true "Profile Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/apparmor/{profileName} [get]
+func (h *RequestHandler) GetAppArmorProfile(w http.ResponseWriter, r *http.Request) {
+	profileName := chi.URLParam(r, "profileName")
+	if profileName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing profile name", nil)
+		return
+	}
+
+	// service
+	profile, err := h.serviceHandler.GetAppArmorProfile(profileName)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve apparmor profile success", profile)
+}
+
+// SetAppArmorProfileMode godoc
+// @Summary set apparmor profile mode
+// @Description switch a custom profile between enforce and complain and reload it
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param profileName path string true "Profile Name"
+// @Param request body SetAppArmorProfileModeRequest true "Profile Mode"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/apparmor/{profileName}/mode [post]
+func (h *RequestHandler) SetAppArmorProfileMode(w http.ResponseWriter, r *http.Request) {
+	profileName := chi.URLParam(r, "profileName")
+	if profileName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing profile name", nil)
+		return
+	}
+
+	// decode request
+	var req SetAppArmorProfileModeRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+
+	// set log: target
+	logger.SetTarget(r.Context(), logger.Target{
+		AppArmorProfile: profileName,
+	})
+	logger.PutExtra(r.Context(), "mode", req.Mode)
+
+	// service
+	if err := h.serviceHandler.SetAppArmorProfileMode(
+		security.ServiceAppArmorModeModel{
+			Name: profileName,
+			Mode: req.Mode,
+		},
+	); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "set mode failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "apparmor profile mode changed", map[string]string{"name": profileName, "mode": req.Mode})
+}
+
+// RemoveAppArmorProfile godoc
+// @Summary remove apparmor profile
+// @Description unload and remove a stored apparmor profile. the built-in profile and profiles in use are refused
+// @Tags security
+// @Param profileName path string true "Profile Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/security/apparmor/{profileName} [delete]
+func (h *RequestHandler) RemoveAppArmorProfile(w http.ResponseWriter, r *http.Request) {
+	profileName := chi.URLParam(r, "profileName")
+	if profileName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing profile name", nil)
+		return
+	}
+
+	// set log: target
+	logger.SetTarget(r.Context(), logger.Target{
+		AppArmorProfile: profileName,
+	})
+
+	// service
+	if err := h.serviceHandler.RemoveAppArmorProfile(profileName); err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "remove failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "apparmor profile removed", map[string]string{"name": profileName})
+}
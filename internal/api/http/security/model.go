@@ -0,0 +1,23 @@
+package security
+
+import "encoding/json"
+
+// == seccomp ==
+type AddSeccompProfileRequest struct {
+	Name string `json:"name" example:"web-restricted"`
+	// OCI seccomp profile (linux.seccomp of the runtime spec)
+	Profile json.RawMessage `json:"profile" swaggertype:"object"`
+}
+
+// == apparmor ==
+type AddAppArmorProfileRequest struct {
+	// must start with "raind-"
+	Name string `json:"name" example:"raind-web"`
+	// profile in the apparmor_parser syntax. must declare "profile <name>"
+	Profile string `json:"profile" example:"#include <tunables/global>\nprofile raind-web flags=(attach_disconnected) {\n  #include <abstractions/base>\n  network inet tcp,\n}"`
+}
+
+type SetAppArmorProfileModeRequest struct {
+	// enforce or complain
+	Mode string `json:"mode" example:"complain"`
+}
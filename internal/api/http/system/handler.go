@@ -0,0 +1,57 @@
+package system
+
+import (
+	"condenser/internal/core/system"
+	"errors"
+	"io"
+	"net/http"
+
+	"condenser/internal/api/http/logger"
+	apimodel "condenser/internal/api/http/utils"
+)
+
+func NewRequestHandler() *RequestHandler {
+	return &RequestHandler{
+		serviceHandler: system.NewSystemService(),
+	}
+}
+
+type RequestHandler struct {
+	serviceHandler system.SystemServiceHandler
+}
+
+// Prune godoc
+// @Summary prune unused resources
+// @Description remove stopped containers, unused images and orphaned directories, cgroups and address allocations
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param request body PruneRequest false "Prune Filters"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/system/prune [post]
+func (h *RequestHandler) Prune(w http.ResponseWriter, r *http.Request) {
+	// decode request
+	var req PruneRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+	logger.PutExtra(r.Context(), "filters", req)
+
+	// service
+	result, err := h.serviceHandler.Prune(
+		system.ServicePruneModel{
+			Age:    req.Age,
+			States: req.States,
+			Labels: req.Labels,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "prune failed: "+err.Error(), result)
+		return
+	}
+	logger.PutExtra(r.Context(), "reclaimed", result)
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "prune completed", result)
+}
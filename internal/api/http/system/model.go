@@ -0,0 +1,9 @@
+package system
+
+// == prune ==
+// body is optional
+type PruneRequest struct {
+	Age    string   `json:"age,omitempty" example:"24h"`
+	States []string `json:"states,omitempty" example:"stopped"`
+	Labels []string `json:"labels,omitempty" example:"team=infra"`
+}
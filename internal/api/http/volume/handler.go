@@ -0,0 +1,154 @@
+package volume
+
+import (
+	"condenser/internal/core/volume"
+	"condenser/internal/store/vsm"
+	"errors"
+	"net/http"
+
+	"condenser/internal/api/http/logger"
+	apimodel "condenser/internal/api/http/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func NewRequestHandler() *RequestHandler {
+	return &RequestHandler{
+		serviceHandler: volume.NewVolumeService(),
+	}
+}
+
+type RequestHandler struct {
+	serviceHandler volume.VolumeServiceHandler
+}
+
+// CreateVolume godoc
+// @Summary create volume
+// @Description create a named volume
+// @Tags volume
+// @Accept json
+// @Produce json
+// @Param request body CreateVolumeRequest true "Volume Name and Labels"
+// @Success 201 {object} apimodel.ApiResponse
+// @Router /v1/volumes [post]
+func (h *RequestHandler) CreateVolume(w http.ResponseWriter, r *http.Request) {
+	// decode request
+	var req CreateVolumeRequest
+	if err := apimodel.DecodeRequestBody(r, &req); err != nil {
+		apimodel.RespondFail(w, http.StatusBadRequest, "invalid json: "+err.Error(), nil)
+		return
+	}
+	logger.PutExtra(r.Context(), "volume", req.Name)
+
+	// service: create
+	volumeInfo, err := h.serviceHandler.Create(
+		volume.ServiceCreateModel{
+			Name:   req.Name,
+			Labels: req.Labels,
+		},
+	)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "create failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusCreated, "volume created", volumeInfo)
+}
+
+// GetVolumeList godoc
+// @Summary get volume list
+// @Description get named volumes with the containers using them
+// @Tags volume
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/volumes [get]
+func (h *RequestHandler) GetVolumeList(w http.ResponseWriter, r *http.Request) {
+	// service
+	volumeList, err := h.serviceHandler.GetVolumeList()
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve volume list success", volumeList)
+}
+
+// GetVolumeByName godoc
+// @Summary get volume info
+// @Description get a named volume
+// @Tags volume
+// @Param volumeName path string true "Volume Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/volumes/{volumeName} [get]
+func (h *RequestHandler) GetVolumeByName(w http.ResponseWriter, r *http.Request) {
+	volumeName := chi.URLParam(r, "volumeName")
+	if volumeName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing volume name", nil)
+		return
+	}
+	logger.PutExtra(r.Context(), "volume", volumeName)
+
+	// service
+	volumeInfo, err := h.serviceHandler.GetVolumeByName(volumeName)
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "retrieve volume info success", volumeInfo)
+}
+
+// RemoveVolume godoc
+// @Summary remove volume
+// @Description remove a named volume and its data. volumes in use by containers are refused
+// @Tags volume
+// @Param volumeName path string true "Volume Name"
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/volumes/{volumeName} [delete]
+func (h *RequestHandler) RemoveVolume(w http.ResponseWriter, r *http.Request) {
+	volumeName := chi.URLParam(r, "volumeName")
+	if volumeName == "" {
+		apimodel.RespondFail(w, http.StatusBadRequest, "missing volume name", nil)
+		return
+	}
+	logger.PutExtra(r.Context(), "volume", volumeName)
+
+	// service
+	if err := h.serviceHandler.Remove(
+		volume.ServiceRemoveModel{
+			Name: volumeName,
+		},
+	); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, vsm.ErrVolumeInUse) {
+			status = http.StatusConflict
+		}
+		apimodel.RespondFail(w, status, "remove failed: "+err.Error(), nil)
+		return
+	}
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "volume removed", map[string]string{"name": volumeName})
+}
+
+// PruneVolumes godoc
+// @Summary prune unused volumes
+// @Description remove named volumes not used by any container
+// @Tags volume
+// @Produce json
+// @Success 200 {object} apimodel.ApiResponse
+// @Router /v1/volumes/prune [post]
+func (h *RequestHandler) PruneVolumes(w http.ResponseWriter, r *http.Request) {
+	// service
+	result, err := h.serviceHandler.Prune()
+	if err != nil {
+		apimodel.RespondFail(w, http.StatusInternalServerError, "prune failed: "+err.Error(), result)
+		return
+	}
+	logger.PutExtra(r.Context(), "reclaimed", result)
+
+	// encode response
+	apimodel.RespondSuccess(w, http.StatusOK, "prune completed", result)
+}
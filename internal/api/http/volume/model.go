@@ -0,0 +1,7 @@
+package volume
+
+// == create ==
+type CreateVolumeRequest struct {
+	Name   string            `json:"name" example:"pgdata"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
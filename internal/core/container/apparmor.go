@@ -0,0 +1,38 @@
+package container
+
+import (
+	"condenser/internal/lsm"
+	"fmt"
+)
+
+// resolveAppArmorProfile returns the profile name passed to the runtime.
+// empty name uses raind-default, or "unconfined" when apparmor is not available on the host.
+// other profiles must be loaded in the kernel
+func (s *ContainerService) resolveAppArmorProfile(name string) (string, error) {
+	if name == lsm.AppArmorUnconfined {
+		return name, nil
+	}
+
+	explicit := name != ""
+	if !explicit {
+		name = lsm.AppArmorProfile
+	}
+	if !s.appArmorHandler.IsEnabled() {
+		if explicit {
+			return "", fmt.Errorf("apparmor is not enabled on this host")
+		}
+		return lsm.AppArmorUnconfined, nil
+	}
+	loaded, err := s.appArmorHandler.IsProfileLoaded(name)
+	if err != nil {
+		return "", err
+	}
+	if !loaded {
+		if explicit {
+			return "", fmt.Errorf("apparmor profile: %s is not loaded", name)
+		}
+		// bootstrap failed to load the built-in profile
+		return lsm.AppArmorUnconfined, nil
+	}
+	return name, nil
+}
@@ -0,0 +1,158 @@
+package container
+
+import (
+	"condenser/internal/utils"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// capability names indexed by number (linux/capability.h)
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// bounding set of a container without capAdd/capDrop
+var defaultCapabilities = []string{
+	"CAP_AUDIT_WRITE",
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_MKNOD",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_RAW",
+	"CAP_SETFCAP",
+	"CAP_SETGID",
+	"CAP_SETPCAP",
+	"CAP_SETUID",
+	"CAP_SYS_CHROOT",
+}
+
+// resolveCapabilities applies capDrop and capAdd to the default set.
+// "ALL" in capDrop starts from an empty set, "ALL" in capAdd grants every capability of the kernel.
+// the default set is left to droplet when it does not support --cap
+func (s *ContainerService) resolveCapabilities(capAdd []string, capDrop []string) ([]string, error) {
+	if len(capAdd) == 0 && len(capDrop) == 0 {
+		supported, err := s.specOptionSupported("--cap")
+		if err != nil {
+			return nil, err
+		}
+		if !supported {
+			return nil, nil
+		}
+	}
+	supported := s.kernelCapabilities()
+
+	normalize := func(list []string) ([]string, bool, error) {
+		var names []string
+		all := false
+		for _, c := range list {
+			name := strings.ToUpper(strings.TrimSpace(c))
+			if name == "ALL" {
+				all = true
+				continue
+			}
+			if !strings.HasPrefix(name, "CAP_") {
+				name = "CAP_" + name
+			}
+			if !slices.Contains(capabilityNames, name) {
+				return nil, false, fmt.Errorf("unknown capability: %s", c)
+			}
+			if !slices.Contains(supported, name) {
+				return nil, false, fmt.Errorf("capability: %s not supported by the kernel", name)
+			}
+			names = append(names, name)
+		}
+		return names, all, nil
+	}
+	add, addAll, err := normalize(capAdd)
+	if err != nil {
+		return nil, err
+	}
+	drop, dropAll, err := normalize(capDrop)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range add {
+		if slices.Contains(drop, c) {
+			return nil, fmt.Errorf("capability: %s is in both capAdd and capDrop", c)
+		}
+	}
+
+	// 1. drop
+	caps := []string{}
+	if !dropAll {
+		for _, c := range defaultCapabilities {
+			if slices.Contains(supported, c) && !slices.Contains(drop, c) {
+				caps = append(caps, c)
+			}
+		}
+	}
+
+	// 2. add
+	if addAll {
+		for _, c := range supported {
+			if !slices.Contains(drop, c) {
+				caps = append(caps, c)
+			}
+		}
+	}
+	caps = append(caps, add...)
+
+	slices.Sort(caps)
+	return slices.Compact(caps), nil
+}
+
+// kernelCapabilities returns capability names known to the running kernel
+func (s *ContainerService) kernelCapabilities() []string {
+	last := len(capabilityNames) - 1
+	if b, err := s.filesystemHandler.ReadFile(utils.CapLastCapPath); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil && n < last {
+			last = n
+		}
+	}
+	return capabilityNames[:last+1]
+}
@@ -0,0 +1,282 @@
+package container
+
+import (
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// resource limit files in the container cgroup (cgroup v2)
+type cgroupLimit struct {
+	file       string
+	controller string
+	value      string
+}
+
+func (s *ContainerService) validateResources(resources ResourceModel) (ResourceModel, error) {
+	var (
+		validated ResourceModel
+		err       error
+	)
+
+	// memory.max / memory.swap.max
+	if resources.MemoryMax != "" {
+		validated.MemoryMax, err = parseCgroupBytes(resources.MemoryMax)
+		if err != nil {
+			return ResourceModel{}, fmt.Errorf("invalid memory.max: %w", err)
+		}
+	}
+	if resources.MemorySwapMax != "" {
+		validated.MemorySwapMax, err = parseCgroupBytes(resources.MemorySwapMax)
+		if err != nil {
+			return ResourceModel{}, fmt.Errorf("invalid memory.swap.max: %w", err)
+		}
+	}
+
+	// cpu.max / cpu.weight
+	if resources.CpuMax != "" {
+		validated.CpuMax, err = parseCgroupCpuMax(resources.CpuMax)
+		if err != nil {
+			return ResourceModel{}, fmt.Errorf("invalid cpu.max: %w", err)
+		}
+	}
+	if resources.CpuWeight != 0 {
+		if resources.CpuWeight < 1 || resources.CpuWeight > 10000 {
+			return ResourceModel{}, fmt.Errorf("invalid cpu.weight: %d (range: 1-10000)", resources.CpuWeight)
+		}
+		validated.CpuWeight = resources.CpuWeight
+	}
+
+	// pids.max
+	if resources.PidsMax != "" {
+		validated.PidsMax, err = parseCgroupMaxInt(resources.PidsMax)
+		if err != nil {
+			return ResourceModel{}, fmt.Errorf("invalid pids.max: %w", err)
+		}
+	}
+
+	// io.max
+	for _, entry := range resources.IoMax {
+		ioMax, err := parseCgroupIoMax(entry)
+		if err != nil {
+			return ResourceModel{}, fmt.Errorf("invalid io.max: %w", err)
+		}
+		validated.IoMax = append(validated.IoMax, ioMax)
+	}
+
+	// check the required controllers are enabled in raind subtree
+	limits := s.buildCgroupLimits(validated)
+	if len(limits) == 0 {
+		return validated, nil
+	}
+	enabled, err := s.readEnabledControllers()
+	if err != nil {
+		return ResourceModel{}, fmt.Errorf("read cgroup controllers failed: %w", err)
+	}
+	for _, l := range limits {
+		if !enabled[l.controller] {
+			return ResourceModel{}, fmt.Errorf("%s requires cgroup controller: %s (not enabled)", l.file, l.controller)
+		}
+	}
+
+	return validated, nil
+}
+
+func (s *ContainerService) readEnabledControllers() (map[string]bool, error) {
+	b, err := s.filesystemHandler.ReadFile(utils.CgroupSubtreeControlPath)
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool)
+	for _, name := range strings.Fields(string(b)) {
+		enabled[name] = true
+	}
+	return enabled, nil
+}
+
+func (s *ContainerService) buildCgroupLimits(resources ResourceModel) []cgroupLimit {
+	var limits []cgroupLimit
+	if resources.MemoryMax != "" {
+		limits = append(limits, cgroupLimit{file: "memory.max", controller: "memory", value: resources.MemoryMax})
+	}
+	if resources.MemorySwapMax != "" {
+		limits = append(limits, cgroupLimit{file: "memory.swap.max", controller: "memory", value: resources.MemorySwapMax})
+	}
+	if resources.CpuMax != "" {
+		limits = append(limits, cgroupLimit{file: "cpu.max", controller: "cpu", value: resources.CpuMax})
+	}
+	if resources.CpuWeight != 0 {
+		limits = append(limits, cgroupLimit{file: "cpu.weight", controller: "cpu", value: strconv.Itoa(resources.CpuWeight)})
+	}
+	if resources.PidsMax != "" {
+		limits = append(limits, cgroupLimit{file: "pids.max", controller: "pids", value: resources.PidsMax})
+	}
+	// io.max accepts one device per write
+	for _, ioMax := range resources.IoMax {
+		limits = append(limits, cgroupLimit{file: "io.max", controller: "io", value: ioMax})
+	}
+	return limits
+}
+
+func (s *ContainerService) applyCgroupResources(containerId string, resources ResourceModel) error {
+	cgroupPath := filepath.Join(utils.CgroupRuntimeDir, containerId)
+
+	for _, l := range s.buildCgroupLimits(resources) {
+		if err := s.filesystemHandler.WriteFile(filepath.Join(cgroupPath, l.file), []byte(l.value), 0o644); err != nil {
+			return fmt.Errorf("write %s failed: %w", l.file, err)
+		}
+	}
+	return nil
+}
+
+func (s *ContainerService) restoreCgroupSubtree(containerId string) error {
+	cgroupPath := filepath.Join(utils.CgroupRuntimeDir, containerId)
+	if _, err := s.filesystemHandler.Stat(cgroupPath); err == nil {
+		// the container may have exited while paused. thaw the cgroup so that the new process is not frozen
+		if frozen, err := s.readFrozenState(cgroupPath); err == nil && frozen {
+			return s.freezeContainer(containerId, false)
+		}
+		return nil
+	} else if !s.filesystemHandler.IsNotExist(err) {
+		return err
+	}
+
+	if err := s.setupCgroupSubtree(containerId); err != nil {
+		return err
+	}
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return err
+	}
+	return s.applyCgroupResources(containerId, fromCsmResources(containerInfo.Resources))
+}
+
+func toCsmResources(resources ResourceModel) csm.ResourceInfo {
+	return csm.ResourceInfo{
+		MemoryMax:     resources.MemoryMax,
+		MemorySwapMax: resources.MemorySwapMax,
+		CpuMax:        resources.CpuMax,
+		CpuWeight:     resources.CpuWeight,
+		PidsMax:       resources.PidsMax,
+		IoMax:         resources.IoMax,
+	}
+}
+
+func fromCsmResources(resources csm.ResourceInfo) ResourceModel {
+	return ResourceModel{
+		MemoryMax:     resources.MemoryMax,
+		MemorySwapMax: resources.MemorySwapMax,
+		CpuMax:        resources.CpuMax,
+		CpuWeight:     resources.CpuWeight,
+		PidsMax:       resources.PidsMax,
+		IoMax:         resources.IoMax,
+	}
+}
+
+// parseCgroupBytes converts "max", "1048576" or "512M" style value to the cgroup byte format
+func parseCgroupBytes(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "max" {
+		return v, nil
+	}
+	if v == "" {
+		return "", fmt.Errorf("empty value")
+	}
+
+	multiplier := int64(1)
+	switch strings.ToLower(v[len(v)-1:]) {
+	case "k":
+		multiplier = 1 << 10
+	case "m":
+		multiplier = 1 << 20
+	case "g":
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		v = v[:len(v)-1]
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("%q is not a byte size", v)
+	}
+	if n > math.MaxInt64/multiplier {
+		return "", fmt.Errorf("%q is too large", v)
+	}
+	return strconv.FormatInt(n*multiplier, 10), nil
+}
+
+// parseCgroupCpuMax validates "$MAX $PERIOD" format. period is optional
+func parseCgroupCpuMax(v string) (string, error) {
+	fields := strings.Fields(v)
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", fmt.Errorf("%q must be \"$MAX [$PERIOD]\"", v)
+	}
+
+	quota := fields[0]
+	if quota != "max" {
+		n, err := strconv.ParseInt(quota, 10, 64)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("invalid quota: %s", quota)
+		}
+	}
+	period := "100000"
+	if len(fields) == 2 {
+		n, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || n < 1000 || n > 1000000 {
+			return "", fmt.Errorf("invalid period: %s (range: 1000-1000000)", fields[1])
+		}
+		period = fields[1]
+	}
+	return quota + " " + period, nil
+}
+
+func parseCgroupMaxInt(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "max" {
+		return v, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("%q must be \"max\" or positive integer", v)
+	}
+	return v, nil
+}
+
+// parseCgroupIoMax validates "$MAJ:$MIN rbps=$N wbps=$N riops=$N wiops=$N" format
+func parseCgroupIoMax(v string) (string, error) {
+	fields := strings.Fields(v)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("%q must be \"$MAJ:$MIN key=value...\"", v)
+	}
+
+	device := strings.SplitN(fields[0], ":", 2)
+	if len(device) != 2 {
+		return "", fmt.Errorf("invalid device: %s", fields[0])
+	}
+	for _, d := range device {
+		if _, err := strconv.ParseUint(d, 10, 32); err != nil {
+			return "", fmt.Errorf("invalid device: %s", fields[0])
+		}
+	}
+
+	for _, f := range fields[1:] {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			return "", fmt.Errorf("invalid key=value: %s", f)
+		}
+		switch kv[0] {
+		case "rbps", "wbps", "riops", "wiops":
+		default:
+			return "", fmt.Errorf("unknown key: %s", kv[0])
+		}
+		if _, err := parseCgroupMaxInt(kv[1]); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", kv[0], err)
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
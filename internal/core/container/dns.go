@@ -0,0 +1,164 @@
+package container
+
+import (
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// used when the host has no usable resolver
+const fallbackDnsServer = "8.8.8.8"
+
+var hostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+var domainPattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// resolveDns validates the create parameters and fills defaults.
+//   - hostname: container id
+//   - servers/search/options: host resolv.conf when no server is specified
+func (s *ContainerService) resolveDns(containerId string, createParameter ServiceCreateModel) (csm.DnsInfo, error) {
+	dns := csm.DnsInfo{
+		Hostname:   createParameter.Hostname,
+		Domainname: createParameter.Domainname,
+		Servers:    createParameter.Dns,
+		Search:     createParameter.DnsSearch,
+		Options:    createParameter.DnsOptions,
+	}
+
+	// 1. hostname and domain
+	if dns.Hostname == "" {
+		dns.Hostname = containerId
+	}
+	if !hostnamePattern.MatchString(dns.Hostname) {
+		return csm.DnsInfo{}, fmt.Errorf("invalid hostname: %s", dns.Hostname)
+	}
+	if dns.Domainname != "" && !domainPattern.MatchString(dns.Domainname) {
+		return csm.DnsInfo{}, fmt.Errorf("invalid domainname: %s", dns.Domainname)
+	}
+
+	// 2. resolver
+	for _, server := range dns.Servers {
+		if net.ParseIP(server) == nil {
+			return csm.DnsInfo{}, fmt.Errorf("invalid dns server: %s", server)
+		}
+	}
+	for _, search := range dns.Search {
+		if !domainPattern.MatchString(search) {
+			return csm.DnsInfo{}, fmt.Errorf("invalid dns search domain: %s", search)
+		}
+	}
+	for _, option := range dns.Options {
+		if option == "" || strings.ContainsAny(option, " \t\n") {
+			return csm.DnsInfo{}, fmt.Errorf("invalid dns option: %q", option)
+		}
+	}
+	if len(dns.Servers) == 0 {
+		host := s.hostResolvConf()
+		dns.Servers = host.Servers
+		if len(dns.Search) == 0 {
+			dns.Search = host.Search
+		}
+		if len(dns.Options) == 0 {
+			dns.Options = host.Options
+		}
+	}
+
+	// 3. extra hosts: "hostname:ip"
+	for _, entry := range createParameter.ExtraHosts {
+		name, addr, ok := strings.Cut(entry, ":")
+		if !ok || !domainPattern.MatchString(name) || net.ParseIP(addr) == nil {
+			return csm.DnsInfo{}, fmt.Errorf("invalid extra host: %s (expected hostname:ip)", entry)
+		}
+		dns.ExtraHosts = append(dns.ExtraHosts, entry)
+	}
+
+	return dns, nil
+}
+
+// hostResolvConf reads the daemon-wide default resolver from the host.
+// loopback servers are not reachable from the container network namespace and are removed.
+// when only loopback servers are left (systemd-resolved stub), the upstream list of systemd-resolved is used
+func (s *ContainerService) hostResolvConf() csm.DnsInfo {
+	for _, path := range []string{utils.HostResolvConfPath, utils.SystemdResolvConfPath} {
+		conf, err := s.parseResolvConf(path)
+		if err != nil {
+			continue
+		}
+		if len(conf.Servers) > 0 {
+			return conf
+		}
+	}
+	return csm.DnsInfo{Servers: []string{fallbackDnsServer}}
+}
+
+func (s *ContainerService) parseResolvConf(path string) (csm.DnsInfo, error) {
+	b, err := s.filesystemHandler.ReadFile(path)
+	if err != nil {
+		return csm.DnsInfo{}, err
+	}
+	var conf csm.DnsInfo
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			ip := net.ParseIP(fields[1])
+			if ip == nil || ip.IsLoopback() {
+				continue
+			}
+			conf.Servers = append(conf.Servers, fields[1])
+		case "search", "domain":
+			conf.Search = fields[1:]
+		case "options":
+			conf.Options = append(conf.Options, fields[1:]...)
+		}
+	}
+	return conf, nil
+}
+
+func buildHostsFile(dns csm.DnsInfo, containerAddr string) string {
+	var b strings.Builder
+	b.WriteString("127.0.0.1 localhost\n")
+	b.WriteString("::1 localhost ip6-localhost ip6-loopback\n")
+
+	names := dns.Hostname
+	if dns.Domainname != "" {
+		names = dns.Hostname + "." + dns.Domainname + " " + dns.Hostname
+	}
+	fmt.Fprintf(&b, "%s %s\n", strings.SplitN(containerAddr, "/", 2)[0], names)
+
+	for _, entry := range dns.ExtraHosts {
+		name, addr, _ := strings.Cut(entry, ":")
+		fmt.Fprintf(&b, "%s %s\n", addr, name)
+	}
+	return b.String()
+}
+
+func buildResolvConf(dns csm.DnsInfo) string {
+	var b strings.Builder
+	for _, server := range dns.Servers {
+		fmt.Fprintf(&b, "nameserver %s\n", server)
+	}
+	if len(dns.Search) > 0 {
+		fmt.Fprintf(&b, "search %s\n", strings.Join(dns.Search, " "))
+	}
+	if len(dns.Options) > 0 {
+		fmt.Fprintf(&b, "options %s\n", strings.Join(dns.Options, " "))
+	}
+	return b.String()
+}
+
+func fromCsmDns(dns csm.DnsInfo) DnsModel {
+	return DnsModel{
+		Hostname:   dns.Hostname,
+		Domainname: dns.Domainname,
+		Servers:    dns.Servers,
+		Search:     dns.Search,
+		Options:    dns.Options,
+		ExtraHosts: dns.ExtraHosts,
+	}
+}
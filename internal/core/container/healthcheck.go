@@ -0,0 +1,152 @@
+package container
+
+import (
+	"condenser/internal/core/image"
+	"condenser/internal/store/csm"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// defaults when neither image nor create request specify them
+	defaultHealthInterval = 30 * time.Second
+	defaultHealthTimeout  = 30 * time.Second
+	defaultHealthRetries  = 3
+	// probe output kept in CSM is truncated to this size
+	maxHealthOutputBytes = 4096
+)
+
+// resolveHealthcheck merges user specified healthcheck into the image healthcheck.
+// empty HealthcheckInfo means healthcheck is disabled
+func (s *ContainerService) resolveHealthcheck(override HealthcheckModel, imageHealthcheck *image.HealthcheckConfig) (csm.HealthcheckInfo, error) {
+	var healthcheck csm.HealthcheckInfo
+	if imageHealthcheck != nil {
+		healthcheck = csm.HealthcheckInfo{
+			Test:        imageHealthcheck.Test,
+			Interval:    imageHealthcheck.Interval,
+			Timeout:     imageHealthcheck.Timeout,
+			StartPeriod: imageHealthcheck.StartPeriod,
+			Retries:     imageHealthcheck.Retries,
+		}
+	}
+
+	// 1. override by user specified values
+	if len(override.Test) > 0 {
+		healthcheck.Test = override.Test
+	}
+	if override.Interval != "" {
+		d, err := parseHealthDuration(override.Interval)
+		if err != nil {
+			return csm.HealthcheckInfo{}, fmt.Errorf("invalid healthcheck interval: %w", err)
+		}
+		healthcheck.Interval = d
+	}
+	if override.Timeout != "" {
+		d, err := parseHealthDuration(override.Timeout)
+		if err != nil {
+			return csm.HealthcheckInfo{}, fmt.Errorf("invalid healthcheck timeout: %w", err)
+		}
+		healthcheck.Timeout = d
+	}
+	if override.StartPeriod != "" {
+		d, err := time.ParseDuration(override.StartPeriod)
+		if err != nil || d < 0 {
+			return csm.HealthcheckInfo{}, fmt.Errorf("invalid healthcheck startPeriod: %s", override.StartPeriod)
+		}
+		healthcheck.StartPeriod = d
+	}
+	if override.Retries < 0 {
+		return csm.HealthcheckInfo{}, fmt.Errorf("invalid healthcheck retries: %d", override.Retries)
+	}
+	if override.Retries > 0 {
+		healthcheck.Retries = override.Retries
+	}
+	if override.RestartAfter < 0 {
+		return csm.HealthcheckInfo{}, fmt.Errorf("invalid healthcheck restartAfter: %d", override.RestartAfter)
+	}
+	healthcheck.RestartAfter = override.RestartAfter
+
+	// 2. validate test
+	//    ["NONE"] disables healthcheck inherited from the image
+	if len(healthcheck.Test) == 0 || healthcheck.Test[0] == "NONE" {
+		return csm.HealthcheckInfo{}, nil
+	}
+	switch healthcheck.Test[0] {
+	case "CMD", "CMD-SHELL":
+		if len(healthcheck.Test) < 2 {
+			return csm.HealthcheckInfo{}, fmt.Errorf("healthcheck %s requires a command", healthcheck.Test[0])
+		}
+	default:
+		return csm.HealthcheckInfo{}, fmt.Errorf("healthcheck test must start with CMD, CMD-SHELL or NONE: %s", healthcheck.Test[0])
+	}
+
+	// 3. fill defaults
+	if healthcheck.Interval == 0 {
+		healthcheck.Interval = defaultHealthInterval
+	}
+	if healthcheck.Timeout == 0 {
+		healthcheck.Timeout = defaultHealthTimeout
+	}
+	if healthcheck.Retries == 0 {
+		healthcheck.Retries = defaultHealthRetries
+	}
+	return healthcheck, nil
+}
+
+func (s *ContainerService) resetHealth(containerId string) error {
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return err
+	}
+	if len(containerInfo.Healthcheck.Test) == 0 {
+		return nil
+	}
+	return s.csmHandler.UpdateHealth(containerId, csm.HealthInfo{Status: "starting"})
+}
+
+// healthProbeCommand converts healthcheck test to the exec entrypoint
+func healthProbeCommand(test []string) []string {
+	if test[0] == "CMD-SHELL" {
+		return []string{"/bin/sh", "-c", strings.Join(test[1:], " ")}
+	}
+	return test[1:]
+}
+
+func parseHealthDuration(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive", v)
+	}
+	return d, nil
+}
+
+func fromCsmHealthcheck(healthcheck csm.HealthcheckInfo) HealthcheckModel {
+	if len(healthcheck.Test) == 0 {
+		return HealthcheckModel{}
+	}
+	return HealthcheckModel{
+		Test:         healthcheck.Test,
+		Interval:     healthcheck.Interval.String(),
+		Timeout:      healthcheck.Timeout.String(),
+		StartPeriod:  healthcheck.StartPeriod.String(),
+		Retries:      healthcheck.Retries,
+		RestartAfter: healthcheck.RestartAfter,
+	}
+}
+
+func fromCsmHealth(health csm.HealthInfo) HealthModel {
+	status := health.Status
+	if status == "" {
+		status = "none"
+	}
+	return HealthModel{
+		Status:        status,
+		FailingStreak: health.FailingStreak,
+		LastOutput:    health.LastOutput,
+		LastCheckedAt: health.LastCheckedAt,
+	}
+}
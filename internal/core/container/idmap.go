@@ -0,0 +1,106 @@
+package container
+
+import (
+	"condenser/internal/utils"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// idMap is the content of /proc/<pid>/uid_map or gid_map
+type idMap []idMapRange
+
+// id reported for ids without mapping, as the kernel does (/proc/sys/kernel/overflowuid)
+const overflowId = 65534
+
+type idMapRange struct {
+	inside  int
+	outside int
+	length  int
+}
+
+// readIdMap reads the id mapping of the process user namespace.
+// nil (identity mapping) is returned when the map cannot be read
+func (s *ContainerService) readIdMap(pid int, mapFile string) idMap {
+	b, err := s.filesystemHandler.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), mapFile))
+	if err != nil {
+		return nil
+	}
+	var m idMap
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		inside, err1 := strconv.Atoi(fields[0])
+		outside, err2 := strconv.Atoi(fields[1])
+		length, err3 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		m = append(m, idMapRange{inside: inside, outside: outside, length: length})
+	}
+	return m
+}
+
+// specIdMapping is an entry of linux.uidMappings and linux.gidMappings in the runtime spec
+type specIdMapping struct {
+	ContainerId int `json:"containerID"`
+	HostId      int `json:"hostID"`
+	Size        int `json:"size"`
+}
+
+// readSpecIdMap reads the user namespace id mapping from the runtime spec (config.json) of the container.
+// unlike readIdMap, it does not need a running process. no mapping in the spec means identity
+func (s *ContainerService) readSpecIdMap(containerId string) (idMap, idMap, error) {
+	b, err := s.filesystemHandler.ReadFile(filepath.Join(utils.ContainerRootDir, containerId, "config.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var spec struct {
+		Linux struct {
+			UidMappings []specIdMapping `json:"uidMappings"`
+			GidMappings []specIdMapping `json:"gidMappings"`
+		} `json:"linux"`
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, nil, err
+	}
+	toIdMap := func(mappings []specIdMapping) idMap {
+		var m idMap
+		for _, r := range mappings {
+			m = append(m, idMapRange{inside: r.ContainerId, outside: r.HostId, length: r.Size})
+		}
+		return m
+	}
+	return toIdMap(spec.Linux.UidMappings), toIdMap(spec.Linux.GidMappings), nil
+}
+
+// toContainer converts host id to the id in the container user namespace.
+// empty map is identity, ids outside the mapping are converted to the overflow id
+func (m idMap) toContainer(hostId int) int {
+	if len(m) == 0 {
+		return hostId
+	}
+	for _, r := range m {
+		if hostId >= r.outside && hostId < r.outside+r.length {
+			return hostId - r.outside + r.inside
+		}
+	}
+	return overflowId
+}
+
+// toHost converts the id in the container user namespace to host id.
+// empty map is identity, ids outside the mapping are converted to the overflow id
+func (m idMap) toHost(containerId int) int {
+	if len(m) == 0 {
+		return containerId
+	}
+	for _, r := range m {
+		if containerId >= r.inside && containerId < r.inside+r.length {
+			return containerId - r.inside + r.outside
+		}
+	}
+	return overflowId
+}
@@ -0,0 +1,200 @@
+package container
+
+import (
+	"condenser/internal/core/image"
+	"condenser/internal/utils"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// processConfig is the container process resolved from the image config and create overrides
+type processConfig struct {
+	Command []string
+	// uid:gid. empty runs as root
+	User string
+	Cwd  string
+}
+
+func (s *ContainerService) resolveProcessConfig(createParameter ServiceCreateModel, imageConfig image.ImageConfigObject, imageRootfs string) (processConfig, error) {
+	// 1. command
+	//    entrypoint override: entrypoint + command
+	//    command only: command replaces entrypoint and cmd of the image
+	//    none: image entrypoint + cmd
+	var command []string
+	switch {
+	case createParameter.Entrypoint != nil:
+		command = slices.Concat(createParameter.Entrypoint, createParameter.Command)
+	case len(createParameter.Command) > 0:
+		command = createParameter.Command
+	default:
+		command = slices.Concat(imageConfig.Entrypoint, imageConfig.Cmd)
+	}
+	if len(command) == 0 {
+		return processConfig{}, fmt.Errorf("no command specified")
+	}
+
+	// 2. working directory
+	cwd := imageConfig.WorkingDir
+	if createParameter.WorkingDir != "" {
+		cwd = createParameter.WorkingDir
+	}
+	if cwd == "" {
+		cwd = "/"
+	}
+	if !filepath.IsAbs(cwd) {
+		return processConfig{}, fmt.Errorf("workingDir must be absolute: %s", cwd)
+	}
+
+	// 3. user
+	user := imageConfig.User
+	if createParameter.User != "" {
+		user = createParameter.User
+	}
+	resolvedUser, err := s.resolveUser(imageRootfs, user)
+	if err != nil {
+		return processConfig{}, err
+	}
+
+	return processConfig{
+		Command: command,
+		User:    resolvedUser,
+		Cwd:     cwd,
+	}, nil
+}
+
+// resolveUser converts user[:group] to uid:gid with /etc/passwd and /etc/group of the image rootfs.
+// numeric ids are accepted without entries
+func (s *ContainerService) resolveUser(imageRootfs string, user string) (string, error) {
+	if user == "" {
+		return "", nil
+	}
+	name, group, hasGroup := strings.Cut(user, ":")
+
+	// 1. uid and primary gid
+	passwd := s.readIdFile(imageRootfs, "/etc/passwd")
+	uid, uidErr := strconv.Atoi(name)
+	gid := 0
+	found := false
+	for _, fields := range passwd {
+		if len(fields) < 4 {
+			continue
+		}
+		entryUid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		if fields[0] == name || (uidErr == nil && entryUid == uid) {
+			uid = entryUid
+			gid, _ = strconv.Atoi(fields[3])
+			found = true
+			break
+		}
+	}
+	if !found && uidErr != nil {
+		return "", fmt.Errorf("user: %s not found in image", name)
+	}
+
+	// 2. group overrides the primary gid
+	if hasGroup {
+		groupGid, err := strconv.Atoi(group)
+		if err != nil {
+			groupGid = -1
+			for _, fields := range s.readIdFile(imageRootfs, "/etc/group") {
+				if len(fields) >= 3 && fields[0] == group {
+					groupGid, _ = strconv.Atoi(fields[2])
+					break
+				}
+			}
+			if groupGid < 0 {
+				return "", fmt.Errorf("group: %s not found in image", group)
+			}
+		}
+		gid = groupGid
+	}
+
+	return fmt.Sprintf("%d:%d", uid, gid), nil
+}
+
+// readIdFile reads colon separated entries of /etc/passwd or /etc/group in the rootfs
+func (s *ContainerService) readIdFile(rootfs string, path string) [][]string {
+	resolved, err := s.resolveInRoot(rootfs, path)
+	if err != nil {
+		return nil
+	}
+	b, err := s.filesystemHandler.ReadFile(resolved)
+	if err != nil {
+		return nil
+	}
+	var entries [][]string
+	for _, line := range strings.Split(string(b), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, strings.Split(line, ":"))
+	}
+	return entries
+}
+
+// exposedPorts returns "port/protocol" keys of the image config in order
+func exposedPorts(imageConfig image.ImageConfigObject) []string {
+	var ports []string
+	for p := range imageConfig.ExposedPorts {
+		ports = append(ports, p)
+	}
+	slices.Sort(ports)
+	return ports
+}
+
+// setupAnonymousVolumes creates a volume directory for each image volume not covered by user mounts.
+// the volume is populated with the image content and removed with the container directory
+func (s *ContainerService) setupAnonymousVolumes(containerId string, imageRootfs string, volumes map[string]struct{}, mounts []string) ([]string, error) {
+	mounted := map[string]bool{}
+	for _, m := range mounts {
+		parts := strings.SplitN(m, ":", 3)
+		if len(parts) >= 2 {
+			mounted[filepath.Clean(parts[1])] = true
+		}
+	}
+
+	var paths []string
+	for p := range volumes {
+		paths = append(paths, filepath.Clean(p))
+	}
+	slices.Sort(paths)
+
+	var anonymous []string
+	for _, p := range paths {
+		if !filepath.IsAbs(p) || mounted[p] {
+			continue
+		}
+		volumeDir := filepath.Join(utils.ContainerRootDir, containerId, "volumes", strings.ReplaceAll(strings.Trim(p, "/"), "/", "_"))
+		if err := s.filesystemHandler.MkdirAll(volumeDir, 0o755); err != nil {
+			return nil, err
+		}
+
+		if err := s.populateVolume(imageRootfs, p, volumeDir); err != nil {
+			return nil, err
+		}
+		anonymous = append(anonymous, volumeDir+":"+p)
+	}
+	return anonymous, nil
+}
+
+// populateVolume copies the image content at path into the volume directory.
+// nothing is copied when the path is not a directory in the image
+func (s *ContainerService) populateVolume(imageRootfs string, path string, volumeDir string) error {
+	src, err := s.resolveInRoot(imageRootfs, path)
+	if err != nil {
+		return err
+	}
+	if info, err := s.filesystemHandler.Stat(src); err == nil && info.IsDir() {
+		cp := s.commandFactory.Command("cp", "-a", src+"/.", volumeDir)
+		if out, err := cp.CombineOutput(); err != nil {
+			return fmt.Errorf("populate volume %s failed: %s: %w", path, strings.TrimSpace(string(out)), err)
+		}
+	}
+	return nil
+}
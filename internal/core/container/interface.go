@@ -1,14 +1,33 @@
 package container
 
+import (
+	"context"
+	"io"
+)
+
 type ContainerServiceHandler interface {
 	Create(createParameter ServiceCreateModel) (string, error)
 	Start(startParameter ServiceStartModel) (string, error)
 	Delete(deleteParameter ServiceDeleteModel) (string, error)
 	Stop(stopParameter ServiceStopModel) (string, error)
+	Kill(killParameter ServiceKillModel) (string, error)
+	Update(updateParameter ServiceUpdateModel) (string, error)
+	Pause(pauseParameter ServicePauseModel) (string, error)
+	Unpause(unpauseParameter ServiceUnpauseModel) (string, error)
+	Wait(ctx context.Context, waitParameter ServiceWaitModel) (WaitResult, error)
 	Exec(execParameter ServiceExecModel) error
-	GetContainerList() ([]ContainerState, error)
+	Healthcheck(healthcheckParameter ServiceHealthcheckModel) (HealthModel, error)
+	GetContainerList(listParameter ServiceListModel) ([]ContainerState, error)
 	GetContainerById(containerId string) (ContainerState, error)
 	GetLogWithTailLines(containerId string, n int) ([]byte, error)
+	GetContainerStats(target string, previous ContainerStats) (ContainerStats, error)
+	GetContainerTop(target string) (ContainerTop, error)
+	GetContainerChanges(target string) (ContainerChanges, error)
+	GetArchive(archiveParameter ServiceArchiveModel) (io.ReadCloser, error)
+	PutArchive(archiveParameter ServiceArchiveModel, r io.Reader) error
+	Export(exportParameter ServiceExportModel) (io.ReadCloser, error)
+	Commit(commitParameter ServiceCommitModel) (CommitResult, error)
+	Rename(renameParameter ServiceRenameModel) (string, error)
 }
 
 type CgroupServiceHandler interface {
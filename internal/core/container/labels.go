@@ -0,0 +1,37 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchLabels reports whether labels satisfy all filters.
+// filter format: "key" (key exists) or "key=value"
+func MatchLabels(labels map[string]string, filters []string) bool {
+	for _, f := range filters {
+		key, value, hasValue := strings.Cut(f, "=")
+		v, ok := labels[key]
+		if !ok {
+			return false
+		}
+		if hasValue && v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveLabels merges image labels and user specified labels. user labels take precedence
+func (s *ContainerService) resolveLabels(imageLabels map[string]string, labels map[string]string) (map[string]string, error) {
+	resolved := map[string]string{}
+	for k, v := range imageLabels {
+		resolved[k] = v
+	}
+	for k, v := range labels {
+		if k == "" || strings.ContainsAny(k, "= \t\n") {
+			return nil, fmt.Errorf("invalid label key: %q", k)
+		}
+		resolved[k] = v
+	}
+	return resolved, nil
+}
@@ -0,0 +1,373 @@
+package container
+
+import (
+	"archive/tar"
+	"condenser/internal/utils"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// overlay marks opaque directories with this xattr. user.* is used when mounted with userxattr
+var opaqueXattrs = []string{"trusted.overlay.opaque", "user.overlay.opaque"}
+
+// isWhiteout reports the entry is an overlay whiteout (char device 0:0)
+func isWhiteout(info fs.FileInfo) bool {
+	if info.Mode()&fs.ModeCharDevice == 0 {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Rdev == 0
+}
+
+// isOpaqueDir reports the directory hides all lower entries
+func isOpaqueDir(path string) bool {
+	buf := make([]byte, 1)
+	for _, attr := range opaqueXattrs {
+		n, err := unix.Lgetxattr(path, attr, buf)
+		if err == nil && n == 1 && buf[0] == 'y' {
+			return true
+		}
+	}
+	return false
+}
+
+// idMapper converts uid/gid between the host and the container user namespace
+type idMapper func(uid, gid int) (int, int)
+
+// writeLayerTar writes overlay upper dir as an OCI layer.
+// whiteouts are converted to .wh.<name> entries, opaque dirs to .wh..wh..opq entries
+func (s *ContainerService) writeLayerTar(diffDir string, w io.Writer, mapId idMapper) error {
+	tw := s.newTarWriter(w, s.filesystemHandler, mapId)
+
+	err := s.filesystemHandler.WalkDir(diffDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(diffDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		// 1. whiteout
+		if isWhiteout(info) {
+			return tw.writeMarker(filepath.Join(filepath.Dir(rel), whiteoutPrefix+filepath.Base(rel)), info)
+		}
+
+		// 2. entry
+		if err := tw.writeEntry(path, rel, info); err != nil {
+			return err
+		}
+
+		// 3. opaque dir marker follows the directory entry
+		if info.IsDir() && isOpaqueDir(path) {
+			return tw.writeMarker(filepath.Join(rel, whiteoutOpaque), info)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// tarSource reads the entries written to the tar: the host filesystem, or a container rootfs opened as root
+type tarSource interface {
+	Open(name string) (*os.File, error)
+	Readlink(name string) (string, error)
+}
+
+type tarWriter struct {
+	*tar.Writer
+	src   tarSource
+	mapId idMapper
+	// hardlinks already written. inode -> first entry name
+	links map[uint64]string
+}
+
+func (s *ContainerService) newTarWriter(w io.Writer, src tarSource, mapId idMapper) *tarWriter {
+	return &tarWriter{
+		Writer: tar.NewWriter(w),
+		src:    src,
+		mapId:  mapId,
+		links:  map[uint64]string{},
+	}
+}
+
+// writeEntry writes the file at path as name. symlinks are not followed
+func (tw *tarWriter) writeEntry(path string, name string, info fs.FileInfo) error {
+	// 1. header from file info
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		var err error
+		if link, err = tw.src.Readlink(path); err != nil {
+			return err
+		}
+	}
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	hdr.Uname, hdr.Gname = "", ""
+	hdr.Format = tar.FormatPAX
+	if tw.mapId != nil {
+		hdr.Uid, hdr.Gid = tw.mapId(hdr.Uid, hdr.Gid)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && info.Mode().IsRegular() && st.Nlink > 1 {
+		if first, ok := tw.links[st.Ino]; ok {
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = first
+			hdr.Size = 0
+		} else {
+			tw.links[st.Ino] = name
+		}
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	// 2. content
+	if hdr.Typeflag == tar.TypeReg {
+		f, err := tw.src.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(tw, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarker writes an empty whiteout entry
+func (tw *tarWriter) writeMarker(name string, info fs.FileInfo) error {
+	return tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		ModTime:  info.ModTime(),
+		Format:   tar.FormatPAX,
+	})
+}
+
+// applyLayerTar extracts an OCI layer onto rootfs, processing whiteouts
+func (s *ContainerService) applyLayerTar(rootfs string, r io.Reader) error {
+	root, err := s.filesystemHandler.OpenRoot(rootfs)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	return s.extractTar(root, ".", r, true, nil)
+}
+
+// extractTar extracts a tar stream into dir under root.
+// every entry is created relative to the root fd and resolved by the kernel on each operation,
+// so symlinks in root never redirect the entries outside even when they are replaced during extraction
+func (s *ContainerService) extractTar(root utils.RootHandler, dir string, r io.Reader, whiteouts bool, mapId idMapper) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar read: %w", err)
+		}
+
+		name := filepath.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if name == "." {
+			continue
+		}
+		if _, err := joinRoot("/", name); err != nil {
+			return fmt.Errorf("invalid path %q: %w", hdr.Name, err)
+		}
+		name = filepath.Join(dir, name)
+		parent, base := filepath.Dir(name), filepath.Base(name)
+
+		// 1. whiteout
+		if whiteouts && base == whiteoutOpaque {
+			entries, err := root.ReadDir(parent)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			for _, e := range entries {
+				if err := root.RemoveAll(filepath.Join(parent, e.Name())); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if whiteouts && strings.HasPrefix(base, whiteoutPrefix) {
+			if err := root.RemoveAll(filepath.Join(parent, strings.TrimPrefix(base, whiteoutPrefix))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 2. existing entry is replaced unless both are directories
+		if st, err := root.Lstat(name); err == nil {
+			if !(st.IsDir() && hdr.Typeflag == tar.TypeDir) {
+				if err := root.RemoveAll(name); err != nil {
+					return err
+				}
+			}
+		}
+		if err := root.MkdirAll(parent, 0o755); err != nil {
+			return err
+		}
+
+		// 3. create entry
+		mode := uint32(hdr.Mode & 0o7777)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := root.MkdirAll(name, os.FileMode(mode&0o777)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			f, err := root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(mode&0o777))
+			if err != nil {
+				return err
+			}
+			_, copyErr := s.filesystemHandler.Copy(f, tr)
+			closeErr := f.Close()
+			if err := errors.Join(copyErr, closeErr); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := root.Symlink(hdr.Linkname, name); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			linkName := filepath.Clean(strings.TrimPrefix(hdr.Linkname, "/"))
+			if _, err := joinRoot("/", linkName); err != nil {
+				return err
+			}
+			if err := root.Link(filepath.Join(dir, linkName), name); err != nil {
+				return fmt.Errorf("hardlink: %w", err)
+			}
+			continue
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			devType := map[byte]uint32{tar.TypeChar: unix.S_IFCHR, tar.TypeBlock: unix.S_IFBLK, tar.TypeFifo: unix.S_IFIFO}[hdr.Typeflag]
+			dev := unix.Mkdev(uint32(hdr.Devmajor), uint32(hdr.Devminor))
+			if err := root.Mknod(name, devType|mode, int(dev)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported tar typeflag %v for %s", hdr.Typeflag, hdr.Name)
+		}
+
+		// 4. owner and times
+		uid, gid := hdr.Uid, hdr.Gid
+		if mapId != nil {
+			uid, gid = mapId(uid, gid)
+		}
+		if err := root.Lchown(name, uid, gid); err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeSymlink {
+			if err := root.Chmod(name, os.FileMode(mode&0o777)|specialModeBits(mode)); err != nil {
+				return err
+			}
+			_ = root.Chtimes(name, time.Now(), hdr.ModTime)
+		}
+	}
+}
+
+// specialModeBits converts setuid/setgid/sticky bits to os.FileMode
+func specialModeBits(mode uint32) os.FileMode {
+	var m os.FileMode
+	if mode&unix.S_ISUID != 0 {
+		m |= os.ModeSetuid
+	}
+	if mode&unix.S_ISGID != 0 {
+		m |= os.ModeSetgid
+	}
+	if mode&unix.S_ISVTX != 0 {
+		m |= os.ModeSticky
+	}
+	return m
+}
+
+// joinRoot joins rel to root lexically. rel must not escape root
+func joinRoot(root, rel string) (string, error) {
+	rel = filepath.Clean(strings.TrimPrefix(rel, "/"))
+	if rel == "." {
+		return root, nil
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path escapes root: %s", rel)
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// maxSymlinks limits symlink resolution in resolveInRoot
+const maxSymlinks = 255
+
+// resolveInRoot resolves path inside root following symlinks as if root were "/".
+// ".." and absolute symlink targets never go above root. missing components are joined as is
+func (s *ContainerService) resolveInRoot(root, path string) (string, error) {
+	current := ""
+	remaining := strings.Split(filepath.ToSlash(path), "/")
+	links := 0
+	for len(remaining) > 0 {
+		c := remaining[0]
+		remaining = remaining[1:]
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			if current == "." {
+				current = ""
+			}
+			continue
+		}
+
+		next := filepath.Join(current, c)
+		st, err := s.filesystemHandler.Lstat(filepath.Join(root, next))
+		if err != nil || st.Mode()&fs.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		// symlink: continue with the target
+		links++
+		if links > maxSymlinks {
+			return "", fmt.Errorf("too many symlinks: %s", path)
+		}
+		target, err := s.filesystemHandler.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			current = ""
+		}
+		remaining = append(strings.Split(target, "/"), remaining...)
+	}
+	return filepath.Join(root, current), nil
+}
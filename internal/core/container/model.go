@@ -13,6 +13,103 @@ type ServiceCreateModel struct {
 	Network string
 	Tty     bool
 	Name    string
+
+	// overrides of the image config. nil entrypoint keeps the image entrypoint
+	Entrypoint []string
+	User       string
+	WorkingDir string
+
+	// hostname defaults to the container id. dns servers default to the host resolv.conf
+	Hostname   string
+	Domainname string
+	Dns        []string
+	DnsSearch  []string
+	DnsOptions []string
+	// "hostname:ip"
+	ExtraHosts []string
+
+	Resources     ResourceModel
+	RestartPolicy RestartPolicyModel
+	Healthcheck   HealthcheckModel
+	AutoRemove    bool
+	Labels        map[string]string
+
+	// mounts the rootfs read-only. /tmp and /run get tmpfs unless mounted by the user
+	ReadOnlyRootfs bool
+	Tmpfs          []TmpfsModel
+
+	// applied to the default capability set. "ALL" is accepted
+	CapAdd  []string
+	CapDrop []string
+	// sets no_new_privs on the container process
+	NoNewPrivileges bool
+	// seccomp profile name. empty uses raind-default, "unconfined" disables seccomp
+	SeccompProfile string
+	// apparmor profile name. empty uses raind-default, "unconfined" disables apparmor
+	AppArmorProfile string
+}
+
+// filters of container list. empty fields are not applied
+type ServiceListModel struct {
+	// "key" or "key=value". all must match
+	Labels []string
+	// any of the states
+	States []string
+	// repository[:reference]. reference is not compared when omitted
+	Image      string
+	NamePrefix string
+	// creation time range
+	CreatedSince  time.Time
+	CreatedBefore time.Time
+}
+
+type ResourceModel struct {
+	MemoryMax     string   `json:"memoryMax,omitempty"`
+	MemorySwapMax string   `json:"memorySwapMax,omitempty"`
+	CpuMax        string   `json:"cpuMax,omitempty"`
+	CpuWeight     int      `json:"cpuWeight,omitempty"`
+	PidsMax       string   `json:"pidsMax,omitempty"`
+	IoMax         []string `json:"ioMax,omitempty"`
+}
+
+type TmpfsModel struct {
+	Target string `json:"target"`
+	// bytes with k/m/g suffix or percentage of memory. empty uses the kernel default
+	Size string `json:"size,omitempty"`
+	// octal permission of the mount root
+	Mode   string `json:"mode,omitempty"`
+	Noexec bool   `json:"noexec,omitempty"`
+}
+
+type RestartPolicyModel struct {
+	Name       string `json:"name"`
+	MaxRetries int    `json:"maxRetries,omitempty"`
+}
+
+// durations are go duration string (e.g. "30s")
+type HealthcheckModel struct {
+	Test         []string `json:"test,omitempty"`
+	Interval     string   `json:"interval,omitempty"`
+	Timeout      string   `json:"timeout,omitempty"`
+	StartPeriod  string   `json:"startPeriod,omitempty"`
+	Retries      int      `json:"retries,omitempty"`
+	RestartAfter int      `json:"restartAfter,omitempty"`
+}
+
+type DnsModel struct {
+	Hostname   string   `json:"hostname"`
+	Domainname string   `json:"domainname"`
+	Servers    []string `json:"servers"`
+	Search     []string `json:"search"`
+	Options    []string `json:"options"`
+	ExtraHosts []string `json:"extraHosts"`
+}
+
+type HealthModel struct {
+	Status        string    `json:"status"`
+	FailingStreak int       `json:"failingStreak"`
+	LastOutput    string    `json:"lastOutput"`
+	LastCheckedAt time.Time `json:"lastCheckedAt"`
 }
 
 type ServiceStartModel struct {
@@ -20,12 +117,88 @@ type ServiceStartModel struct {
 	Tty         bool
 }
 
+type ServiceHealthcheckModel struct {
+	ContainerId string
+}
+
 type ServiceDeleteModel struct {
 	ContainerId string
 }
 
 type ServiceStopModel struct {
 	ContainerId string
+	// seconds to wait before SIGKILL. nil uses the default timeout
+	Timeout *int
+	// overrides the image stop signal
+	Signal string
+}
+
+type ServiceKillModel struct {
+	ContainerId string
+	Signal      string
+}
+
+type ServicePauseModel struct {
+	ContainerId string
+}
+
+type ServiceUnpauseModel struct {
+	ContainerId string
+}
+
+type ServiceUpdateModel struct {
+	ContainerId string
+	Resources   ResourceModel
+}
+
+type ServiceWaitModel struct {
+	ContainerId string
+	// stopped (default), not-running or removed
+	Condition string
+	// seconds. 0 uses the server side limit
+	Timeout int
+}
+
+type WaitResult struct {
+	ContainerId    string `json:"containerId"`
+	State          string `json:"state"`
+	ExitCode       *int   `json:"exitCode"`
+	FinishedReason string `json:"finishedReason"`
+	OomKilled      bool   `json:"oomKilled"`
+}
+
+type ServiceCommitModel struct {
+	ContainerId string
+	// repository:tag
+	Image   string
+	Cmd     []string
+	Env     []string
+	Comment string
+}
+
+type CommitResult struct {
+	ContainerId string `json:"containerId"`
+	Repository  string `json:"repository"`
+	Reference   string `json:"reference"`
+	Digest      string `json:"digest"`
+	LayerDigest string `json:"layerDigest"`
+	LayerSize   int64  `json:"layerSize"`
+}
+
+// path is the absolute path in the container rootfs
+type ServiceArchiveModel struct {
+	ContainerId string
+	Path        string
+}
+
+type ServiceRenameModel struct {
+	ContainerId string
+	Name        string
+}
+
+type ServiceExportModel struct {
+	ContainerId string
+	Gzip        bool
 }
 
 type ServiceExecModel struct {
@@ -49,11 +222,125 @@ type ContainerState struct {
 	Reference   string   `json:"imageReference"`
 	Command     []string `json:"command"`
 
+	User         string            `json:"user"`
+	ExposedPorts []string          `json:"exposedPorts"`
+	Labels       map[string]string `json:"labels"`
+	Dns          DnsModel          `json:"dns"`
+
+	ReadOnlyRootfs bool         `json:"readOnlyRootfs"`
+	Tmpfs          []TmpfsModel `json:"tmpfs"`
+
+	Capabilities    []string `json:"capabilities"`
+	NoNewPrivileges bool     `json:"noNewPrivileges"`
+	SeccompProfile  string   `json:"seccompProfile"`
+	AppArmorProfile string   `json:"appArmorProfile"`
+
 	Address  string        `json:"address"`
 	Forwards []ForwardInfo `json:"forwards"`
 
+	Resources     ResourceModel      `json:"resources"`
+	RestartPolicy RestartPolicyModel `json:"restartPolicy"`
+	RestartCount  int                `json:"restartCount"`
+	Healthcheck   HealthcheckModel   `json:"healthcheck"`
+	Health        HealthModel        `json:"health"`
+	StopSignal    string             `json:"stopSignal"`
+	AutoRemove    bool               `json:"autoRemove"`
+
+	// nil when the container has not exited yet or the exit status is lost
+	ExitCode       *int   `json:"exitCode"`
+	FinishedReason string `json:"finishedReason"`
+	ExitSignal     string `json:"exitSignal"`
+	OomKilled      bool   `json:"oomKilled"`
+
+	// time the container was created. not changed when the container is re-created on restart
+	Created    time.Time `json:"created"`
 	CreatingAt time.Time `json:"creatingAt"`
 	CreatedAt  time.Time `json:"createdAt"`
 	StartedAt  time.Time `json:"statedAt"`
 	StoppedAt  time.Time `json:"stoppedAt"`
 }
+
+type ContainerStats struct {
+	ContainerId string       `json:"containerId"`
+	ReadAt      time.Time    `json:"readAt"`
+	OnlineCpus  int          `json:"onlineCpus"`
+	Memory      MemoryStats  `json:"memory"`
+	Cpu         CpuStats     `json:"cpu"`
+	Io          []IoStats    `json:"io"`
+	Pids        PidsStats    `json:"pids"`
+	Network     NetworkStats `json:"network"`
+}
+
+type MemoryStats struct {
+	Current uint64            `json:"current"`
+	Max     string            `json:"max"`
+	Stat    map[string]uint64 `json:"stat"`
+}
+
+type CpuStats struct {
+	UsageUsec     uint64  `json:"usageUsec"`
+	UserUsec      uint64  `json:"userUsec"`
+	SystemUsec    uint64  `json:"systemUsec"`
+	NrPeriods     uint64  `json:"nrPeriods"`
+	NrThrottled   uint64  `json:"nrThrottled"`
+	ThrottledUsec uint64  `json:"throttledUsec"`
+	Percent       float64 `json:"percent"`
+}
+
+type IoStats struct {
+	Device     string `json:"device"`
+	ReadBytes  uint64 `json:"readBytes"`
+	WriteBytes uint64 `json:"writeBytes"`
+	ReadIos    uint64 `json:"readIos"`
+	WriteIos   uint64 `json:"writeIos"`
+}
+
+type PidsStats struct {
+	Current uint64 `json:"current"`
+	Max     string `json:"max"`
+}
+
+// counters are seen from the container
+type NetworkStats struct {
+	Interface string `json:"interface"`
+	RxBytes   uint64 `json:"rxBytes"`
+	RxPackets uint64 `json:"rxPackets"`
+	RxErrors  uint64 `json:"rxErrors"`
+	RxDropped uint64 `json:"rxDropped"`
+	TxBytes   uint64 `json:"txBytes"`
+	TxPackets uint64 `json:"txPackets"`
+	TxErrors  uint64 `json:"txErrors"`
+	TxDropped uint64 `json:"txDropped"`
+}
+
+type ContainerTop struct {
+	ContainerId string        `json:"containerId"`
+	Processes   []ProcessInfo `json:"processes"`
+}
+
+// pid and ppid are in the container pid namespace
+type ProcessInfo struct {
+	Pid     int    `json:"pid"`
+	HostPid int    `json:"hostPid"`
+	Ppid    int    `json:"ppid"`
+	User    string `json:"user"`
+	Uid     int    `json:"uid"`
+	State   string `json:"state"`
+	Rss     uint64 `json:"rss"`
+	CpuTime string `json:"cpuTime"`
+	Command string `json:"command"`
+}
+
+type ContainerChanges struct {
+	ContainerId string       `json:"containerId"`
+	Changes     []FileChange `json:"changes"`
+}
+
+// kind: added, modified or deleted
+type FileChange struct {
+	Path       string    `json:"path"`
+	Kind       string    `json:"kind"`
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
@@ -0,0 +1,28 @@
+package container
+
+import "condenser/internal/lsm"
+
+// resolveSeccompProfile returns the profile name and the path passed to the runtime.
+// empty name uses the built-in profile, or "unconfined" when droplet does not support --seccomp.
+// "unconfined" disables seccomp and has no path
+func (s *ContainerService) resolveSeccompProfile(name string) (string, string, error) {
+	if name == "" {
+		supported, err := s.specOptionSupported("--seccomp")
+		if err != nil {
+			return "", "", err
+		}
+		if !supported {
+			return lsm.SeccompUnconfined, "", nil
+		}
+		name = lsm.SeccompDefaultProfile
+	}
+	if name == lsm.SeccompUnconfined {
+		return name, "", nil
+	}
+	// the profile is validated again. files edited on the host may contain unknown syscalls
+	path, err := s.seccompHandler.GetProfilePath(name)
+	if err != nil {
+		return "", "", err
+	}
+	return name, path, nil
+}
@@ -3,11 +3,15 @@ package container
 import (
 	"condenser/internal/core/image"
 	"condenser/internal/core/network"
+	"condenser/internal/core/volume"
+	"condenser/internal/lsm"
 	"condenser/internal/runtime"
 	"condenser/internal/runtime/droplet"
 	"condenser/internal/store/csm"
 	"condenser/internal/store/ilm"
 	"condenser/internal/store/ipam"
+	"condenser/internal/store/npm"
+	"condenser/internal/store/vsm"
 	"condenser/internal/utils"
 )
 
@@ -16,13 +20,18 @@ func NewContaierService() *ContainerService {
 		filesystemHandler: utils.NewFilesystemExecutor(),
 		commandFactory:    utils.NewCommandFactory(),
 		runtimeHandler:    droplet.NewDropletHandler(),
+		seccompHandler:    lsm.NewSeccompManager(),
+		appArmorHandler:   lsm.NewAppArmorManager(),
 
 		ipamHandler: ipam.NewIpamManager(ipam.NewIpamStore(utils.IpamStorePath)),
 		ilmHandler:  ilm.NewIlmManager(ilm.NewIlmStore(utils.IlmStorePath)),
 		csmHandler:  csm.NewCsmManager(csm.NewCsmStore(utils.CsmStorePath)),
+		npmHandler:  npm.NewNpmManager(npm.NewNpmStore(utils.NpmStorePath)),
+		vsmHandler:  vsm.NewVsmManager(vsm.NewVsmStore(utils.VsmStorePath)),
 
 		imageServiceHandler:   image.NewImageService(),
 		networkServiceHandler: network.NewNetworkService(),
+		volumeServiceHandler:  volume.NewVolumeService(),
 	}
 }
 
@@ -30,13 +39,18 @@ type ContainerService struct {
 	filesystemHandler utils.FilesystemHandler
 	commandFactory    utils.CommandFactory
 	runtimeHandler    runtime.RuntimeHandler
+	seccompHandler    lsm.SeccompHandler
+	appArmorHandler   lsm.AppArmorHandler
 
 	ipamHandler ipam.IpamHandler
 	ilmHandler  ilm.IlmHandler
 	csmHandler  csm.CsmHandler
+	npmHandler  npm.NpmHandler
+	vsmHandler  vsm.VsmHandler
 
 	imageServiceHandler   image.ImageServiceHandler
 	networkServiceHandler network.NetworkServiceHandler
+	volumeServiceHandler  volume.VolumeServiceHandler
 }
 
 func (s *ContainerService) getContainerState(containerId string) (string, error) {
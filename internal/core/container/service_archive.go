@@ -0,0 +1,268 @@
+package container
+
+import (
+	"bufio"
+	"compress/gzip"
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// == service: get archive ==
+func (s *ContainerService) GetArchive(archiveParameter ServiceArchiveModel) (io.ReadCloser, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(archiveParameter.ContainerId)
+	if err != nil {
+		return nil, fmt.Errorf("container: %s not found", archiveParameter.ContainerId)
+	}
+	rel, err := archiveRelPath(archiveParameter.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. open container rootfs
+	rootfs, err := s.openContainerRootfs(containerId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. source path is resolved in the rootfs. the last component is not followed
+	if _, err := rootfs.root.Lstat(rel); err != nil {
+		rootfs.release()
+		return nil, fmt.Errorf("path: %s: %w", archiveParameter.Path, err)
+	}
+	name := ""
+	if rel != "." {
+		name = filepath.Base(rel)
+	}
+
+	// 3. stream tar. entries are named from the base name of the path
+	pr, pw := io.Pipe()
+	go func() {
+		defer rootfs.release()
+		tw := s.newTarWriter(pw, rootfs.root, rootfs.toContainer)
+		err := rootfs.root.WalkDir(rel, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			entryRel, err := filepath.Rel(rel, path)
+			if err != nil {
+				return err
+			}
+			entryName := filepath.Join(name, entryRel)
+			if entryName == "." {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return tw.writeEntry(path, entryName, info)
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// =================================
+
+// == service: put archive ==
+func (s *ContainerService) PutArchive(archiveParameter ServiceArchiveModel, r io.Reader) error {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(archiveParameter.ContainerId)
+	if err != nil {
+		return fmt.Errorf("container: %s not found", archiveParameter.ContainerId)
+	}
+	rel, err := archiveRelPath(archiveParameter.Path)
+	if err != nil {
+		return err
+	}
+
+	// 1. open container rootfs
+	rootfs, err := s.openContainerRootfs(containerId)
+	if err != nil {
+		return err
+	}
+	defer rootfs.release()
+
+	// 2. destination must be an existing directory. symlinks are followed inside the rootfs
+	info, err := rootfs.root.Stat(rel)
+	if err != nil {
+		return fmt.Errorf("path: %s: %w", archiveParameter.Path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path: %s is not a directory", archiveParameter.Path)
+	}
+
+	// 3. extract. gzip compressed tar is also accepted
+	br := bufio.NewReader(r)
+	var tr io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("gzip reader: %w", err)
+		}
+		defer gzr.Close()
+		tr = gzr
+	}
+	if err := s.extractTar(rootfs.root, rel, tr, false, rootfs.toHost); err != nil {
+		return fmt.Errorf("extract archive failed: %w", err)
+	}
+	return nil
+}
+
+// archiveRelPath converts the requested container path to the path relative to rootfs
+func archiveRelPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	rel := filepath.Clean(strings.TrimPrefix(path, "/"))
+	if _, err := joinRoot("/", rel); err != nil {
+		return "", err
+	}
+	return rel, nil
+}
+
+type containerRootfs struct {
+	// container rootfs opened as "/". paths are resolved inside it
+	root utils.RootHandler
+	// uid/gid conversion between host and the container user namespace
+	toContainer idMapper
+	toHost      idMapper
+	release     func()
+}
+
+// openContainerRootfs returns the merged view of the container rootfs.
+//   - with init process (created/running/paused): merged directory, or /proc/<pid>/root when the overlay is mounted only in the container mount namespace
+//   - stopped: image rootfs and diff directory are mounted on merged until release is called.
+//     the container lock is held meanwhile, so that start and delete do not use the same upper dir at once
+func (s *ContainerService) openContainerRootfs(containerId string) (containerRootfs, error) {
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return containerRootfs{}, err
+	}
+	containerDir := filepath.Join(utils.ContainerRootDir, containerId)
+	mergedDir := filepath.Join(containerDir, "merged")
+
+	// ids are mapped with the runtime spec. it is available without the process
+	uidMap, gidMap, err := s.readSpecIdMap(containerId)
+	if err != nil {
+		return containerRootfs{}, fmt.Errorf("read id mapping failed: %w", err)
+	}
+	rootfs := containerRootfs{
+		toContainer: func(uid, gid int) (int, int) {
+			return uidMap.toContainer(uid), gidMap.toContainer(gid)
+		},
+		toHost: func(uid, gid int) (int, int) {
+			return uidMap.toHost(uid), gidMap.toHost(gid)
+		},
+	}
+
+	switch {
+	case containerInfo.State == "running" || containerInfo.State == "paused" || (containerInfo.State == "created" && containerInfo.Pid > 0):
+		path := mergedDir
+		if !s.filesystemHandler.IsMountPoint(mergedDir) {
+			path = filepath.Join("/proc", strconv.Itoa(containerInfo.Pid), "root")
+		}
+		root, err := s.filesystemHandler.OpenRoot(path)
+		if err != nil {
+			return containerRootfs{}, err
+		}
+		rootfs.root = root
+		rootfs.release = func() {
+			_ = root.Close()
+		}
+		return rootfs, nil
+
+	case containerInfo.State == "created" || containerInfo.State == "stopped":
+		unlock, err := s.lockContainer(containerId)
+		if err != nil {
+			return containerRootfs{}, err
+		}
+		// the state may be changed while waiting the lock
+		containerInfo, err = s.csmHandler.GetContainerById(containerId)
+		if err != nil {
+			unlock()
+			return containerRootfs{}, err
+		}
+		if containerInfo.State != "stopped" && !(containerInfo.State == "created" && containerInfo.Pid <= 0) {
+			unlock()
+			return containerRootfs{}, fmt.Errorf("container: %s state changed: %s", containerId, containerInfo.State)
+		}
+
+		mounted := false
+		if !s.filesystemHandler.IsMountPoint(mergedDir) {
+			if err := s.mountContainerOverlay(containerInfo, containerDir); err != nil {
+				unlock()
+				return containerRootfs{}, fmt.Errorf("mount rootfs failed: %w", err)
+			}
+			mounted = true
+		}
+		root, err := s.filesystemHandler.OpenRoot(mergedDir)
+		if err != nil {
+			if mounted {
+				_ = unix.Unmount(mergedDir, 0)
+			}
+			unlock()
+			return containerRootfs{}, err
+		}
+		rootfs.root = root
+		rootfs.release = func() {
+			_ = root.Close()
+			if mounted {
+				_ = unix.Unmount(mergedDir, 0)
+			}
+			unlock()
+		}
+		return rootfs, nil
+
+	default:
+		return containerRootfs{}, fmt.Errorf("operation not allowed to current container status: %s", containerInfo.State)
+	}
+}
+
+// lockContainer takes the exclusive lock of the container directory.
+// start, delete and the temporary rootfs mount are serialized with it.
+// nothing is locked when the directory does not exist (yet or any more)
+func (s *ContainerService) lockContainer(containerId string) (func(), error) {
+	lockPath := filepath.Join(utils.ContainerRootDir, containerId, "container.lock")
+	lf, err := s.filesystemHandler.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		if s.filesystemHandler.IsNotExist(err) {
+			return func() {}, nil
+		}
+		return nil, err
+	}
+	if err := s.filesystemHandler.Flock(int(lf.Fd()), syscall.LOCK_EX); err != nil {
+		lf.Close()
+		return nil, err
+	}
+	return func() {
+		_ = s.filesystemHandler.Flock(int(lf.Fd()), syscall.LOCK_UN)
+		lf.Close()
+	}, nil
+}
+
+func (s *ContainerService) mountContainerOverlay(containerInfo csm.ContainerInfo, containerDir string) error {
+	lowerDir, err := s.ilmHandler.GetRootfsPath(containerInfo.Repository, containerInfo.Reference)
+	if err != nil {
+		return err
+	}
+	options := fmt.Sprintf(
+		"lowerdir=%s,upperdir=%s,workdir=%s",
+		lowerDir, filepath.Join(containerDir, "diff"), filepath.Join(containerDir, "work"),
+	)
+	return unix.Mount("overlay", filepath.Join(containerDir, "merged"), "overlay", 0, options)
+}
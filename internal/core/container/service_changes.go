@@ -0,0 +1,127 @@
+package container
+
+import (
+	"condenser/internal/utils"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// == service: changes ==
+func (s *ContainerService) GetContainerChanges(target string) (ContainerChanges, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(target)
+	if err != nil {
+		return ContainerChanges{}, fmt.Errorf("container: %s not found", target)
+	}
+
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return ContainerChanges{}, err
+	}
+	if containerInfo.State == "creating" {
+		return ContainerChanges{}, fmt.Errorf("changes not allowed to current container status: %s", containerInfo.State)
+	}
+
+	// 1. image rootfs (lower dir)
+	lowerDir, err := s.ilmHandler.GetRootfsPath(containerInfo.Repository, containerInfo.Reference)
+	if err != nil {
+		return ContainerChanges{}, fmt.Errorf("base image %s:%s: %w", containerInfo.Repository, containerInfo.Reference, err)
+	}
+	//    image entries are resolved in the image rootfs. symlinks in the image do not lead outside
+	lowerRoot, err := s.filesystemHandler.OpenRoot(lowerDir)
+	if err != nil {
+		return ContainerChanges{}, fmt.Errorf("open image rootfs failed: %w", err)
+	}
+	defer lowerRoot.Close()
+
+	// 2. walk upper dir
+	upperDir := filepath.Join(utils.ContainerRootDir, containerId, "diff")
+	changes := []FileChange{}
+	err = s.filesystemHandler.WalkDir(upperDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(upperDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// whiteout: deleted entry. size and mode are of the image entry
+		if isWhiteout(info) {
+			change := FileChange{Path: "/" + rel, Kind: "deleted"}
+			if lowerInfo, err := lowerRoot.Lstat(rel); err == nil {
+				change.Size, change.Mode = lowerInfo.Size(), lowerInfo.Mode().String()
+			}
+			changes = append(changes, change)
+			return nil
+		}
+
+		kind := "added"
+		if _, err := lowerRoot.Lstat(rel); err == nil {
+			kind = "modified"
+		}
+		changes = append(changes, FileChange{
+			Path:       "/" + rel,
+			Kind:       kind,
+			Size:       info.Size(),
+			Mode:       info.Mode().String(),
+			ModifiedAt: info.ModTime(),
+		})
+
+		// opaque dir: image entries not in the upper dir are deleted
+		if info.IsDir() && isOpaqueDir(path) && kind == "modified" {
+			deleted, err := s.opaqueDeletedEntries(lowerRoot, rel, path, "/"+rel)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, deleted...)
+		}
+		return nil
+	})
+	if err != nil {
+		return ContainerChanges{}, fmt.Errorf("walk diff directory failed: %w", err)
+	}
+	slices.SortFunc(changes, func(a, b FileChange) int { return strings.Compare(a.Path, b.Path) })
+
+	return ContainerChanges{
+		ContainerId: containerId,
+		Changes:     changes,
+	}, nil
+}
+
+// opaqueDeletedEntries lists direct children of the image directory hidden by the opaque upper directory
+func (s *ContainerService) opaqueDeletedEntries(lowerRoot utils.RootHandler, lowerPath, upperPath, relDir string) ([]FileChange, error) {
+	entries, err := lowerRoot.ReadDir(lowerPath)
+	if err != nil {
+		if s.filesystemHandler.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var deleted []FileChange
+	for _, e := range entries {
+		if _, err := s.filesystemHandler.Lstat(filepath.Join(upperPath, e.Name())); err == nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		deleted = append(deleted, FileChange{
+			Path: filepath.Join(relDir, e.Name()),
+			Kind: "deleted",
+			Size: info.Size(),
+			Mode: info.Mode().String(),
+		})
+	}
+	return deleted, nil
+}
@@ -0,0 +1,379 @@
+package container
+
+import (
+	"compress/gzip"
+	"condenser/internal/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	configMediaType   = "application/vnd.oci.image.config.v1+json"
+	layerMediaType    = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// == service: commit ==
+func (s *ContainerService) Commit(commitParameter ServiceCommitModel) (result CommitResult, err error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(commitParameter.ContainerId)
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("container: %s not found", commitParameter.ContainerId)
+	}
+
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	switch containerInfo.State {
+	case "created", "running", "paused", "stopped":
+	default:
+		return CommitResult{}, fmt.Errorf("commit operation not allowed to current container status: %s", containerInfo.State)
+	}
+
+	// 1. validate new image reference
+	if strings.Contains(commitParameter.Image, "@") {
+		return CommitResult{}, fmt.Errorf("image must be repository:tag: %s", commitParameter.Image)
+	}
+	repo, ref, err := s.parseImageRef(commitParameter.Image)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	if s.ilmHandler.IsImageExist(repo, ref) {
+		return CommitResult{}, fmt.Errorf("image: %s:%s already exists", repo, ref)
+	}
+	for _, part := range strings.Split(repo+"/"+ref, "/") {
+		if part == "" || part == "." || part == ".." {
+			return CommitResult{}, fmt.Errorf("invalid image: %s", commitParameter.Image)
+		}
+	}
+	repoOut := filepath.Join(utils.LayerRootDir, repo, ref)
+	if _, err := s.filesystemHandler.Stat(repoOut); err == nil {
+		return CommitResult{}, fmt.Errorf("image directory already exists: %s", repoOut)
+	}
+
+	// 2. base image
+	baseBundle, err := s.ilmHandler.GetBundlePath(containerInfo.Repository, containerInfo.Reference)
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("base image %s:%s: %w", containerInfo.Repository, containerInfo.Reference, err)
+	}
+	baseConfigPath, err := s.ilmHandler.GetConfigPath(containerInfo.Repository, containerInfo.Reference)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	baseRootfs, err := s.ilmHandler.GetRootfsPath(containerInfo.Repository, containerInfo.Reference)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	baseManifest, err := s.readImageManifest(baseBundle)
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("read base manifest failed: %w", err)
+	}
+
+	// 3. create output directory. removed when the commit is not completed
+	blobDir := filepath.Join(repoOut, "blobs")
+	rootfsPath := filepath.Join(repoOut, "rootfs")
+	for _, dir := range []string{repoOut, blobDir, rootfsPath} {
+		if err := s.filesystemHandler.MkdirAll(dir, 0o755); err != nil {
+			return CommitResult{}, err
+		}
+	}
+	defer func() {
+		if err != nil {
+			_ = s.filesystemHandler.RemoveAll(repoOut)
+		}
+	}()
+
+	// 4. create layer from the upper dir
+	layer, diffId, err := s.writeCommitLayer(containerId, containerInfo.State, blobDir)
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("create layer failed: %w", err)
+	}
+
+	// 5. link base layer blobs
+	for _, l := range baseManifest.Layers {
+		name := digestToFilename(l.Digest)
+		if err := s.filesystemHandler.Link(filepath.Join(baseBundle, "blobs", name), filepath.Join(blobDir, name)); err != nil {
+			return CommitResult{}, fmt.Errorf("link base layer failed: %w", err)
+		}
+	}
+
+	// 6. write image config with the requested changes
+	config, err := s.commitImageConfig(baseConfigPath, containerId, diffId, commitParameter)
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("create config failed: %w", err)
+	}
+	configDigest := sha256Digest(config)
+	configPath := filepath.Join(repoOut, "config.json")
+	if err := s.filesystemHandler.WriteFile(filepath.Join(blobDir, digestToFilename(configDigest)), config, 0o644); err != nil {
+		return CommitResult{}, err
+	}
+	if err := s.filesystemHandler.WriteFile(configPath, config, 0o644); err != nil {
+		return CommitResult{}, err
+	}
+
+	// 7. write manifest
+	manifest := imageManifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		Config:        imageDescriptor{MediaType: configMediaType, Size: int64(len(config)), Digest: configDigest},
+		Layers:        append(baseManifest.Layers, layer),
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	if err := s.filesystemHandler.WriteFile(filepath.Join(repoOut, "manifest.json"), manifestBytes, 0o644); err != nil {
+		return CommitResult{}, err
+	}
+
+	// 8. build rootfs: base rootfs + new layer
+	cp := s.commandFactory.Command("cp", "-a", baseRootfs+"/.", rootfsPath)
+	if out, err := cp.CombineOutput(); err != nil {
+		return CommitResult{}, fmt.Errorf("copy base rootfs failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	if err := s.applyCommitLayer(rootfsPath, filepath.Join(blobDir, digestToFilename(layer.Digest))); err != nil {
+		return CommitResult{}, fmt.Errorf("apply layer failed: %w", err)
+	}
+
+	// 9. register image
+	if err := s.ilmHandler.StoreImage(repo, ref, repoOut, configPath, rootfsPath); err != nil {
+		return CommitResult{}, err
+	}
+
+	return CommitResult{
+		ContainerId: containerId,
+		Repository:  repo,
+		Reference:   ref,
+		Digest:      configDigest,
+		LayerDigest: layer.Digest,
+		LayerSize:   layer.Size,
+	}, nil
+}
+
+type imageDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+	Digest    string `json:"digest"`
+}
+
+type imageManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        imageDescriptor   `json:"config"`
+	Layers        []imageDescriptor `json:"layers"`
+}
+
+// readImageManifest reads the platform manifest of the image bundle.
+// manifest.selected.json exists when the pulled manifest was a list
+func (s *ContainerService) readImageManifest(bundlePath string) (imageManifest, error) {
+	b, err := s.filesystemHandler.ReadFile(filepath.Join(bundlePath, "manifest.selected.json"))
+	if err != nil {
+		if !s.filesystemHandler.IsNotExist(err) {
+			return imageManifest{}, err
+		}
+		if b, err = s.filesystemHandler.ReadFile(filepath.Join(bundlePath, "manifest.json")); err != nil {
+			return imageManifest{}, err
+		}
+	}
+	var m imageManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return imageManifest{}, err
+	}
+	return m, nil
+}
+
+// writeCommitLayer writes the upper dir as a gzip layer blob and returns the descriptor and diff id.
+// a running container is frozen only while the upper dir is copied to a snapshot, the layer is built from the snapshot
+func (s *ContainerService) writeCommitLayer(containerId string, state string, blobDir string) (imageDescriptor, string, error) {
+	diffDir := filepath.Join(utils.ContainerRootDir, containerId, "diff")
+	if state == "running" {
+		snapshotDir := filepath.Join(utils.ContainerRootDir, containerId, "diff.commit")
+		if err := s.snapshotUpperDir(containerId, diffDir, snapshotDir); err != nil {
+			return imageDescriptor{}, "", err
+		}
+		defer s.filesystemHandler.RemoveAll(snapshotDir)
+		diffDir = snapshotDir
+	}
+
+	// files in the upper dir are owned by host ids. map them into the container user namespace
+	uidMap, gidMap, err := s.readSpecIdMap(containerId)
+	if err != nil {
+		return imageDescriptor{}, "", fmt.Errorf("read id mapping failed: %w", err)
+	}
+	mapId := func(uid, gid int) (int, int) {
+		return uidMap.toContainer(uid), gidMap.toContainer(gid)
+	}
+
+	tmpPath := filepath.Join(blobDir, "layer.tmp")
+	f, err := s.filesystemHandler.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return imageDescriptor{}, "", err
+	}
+	defer s.filesystemHandler.Remove(tmpPath)
+
+	// digest: sha256 of the compressed blob, diff id: sha256 of the tar
+	blobHash, tarHash := sha256.New(), sha256.New()
+	counter := &countWriter{w: io.MultiWriter(f, blobHash)}
+	gzw := gzip.NewWriter(counter)
+	writeErr := s.writeLayerTar(diffDir, io.MultiWriter(gzw, tarHash), mapId)
+	if writeErr == nil {
+		writeErr = gzw.Close()
+	}
+	closeErr := f.Close()
+	if writeErr != nil {
+		return imageDescriptor{}, "", writeErr
+	}
+	if closeErr != nil {
+		return imageDescriptor{}, "", closeErr
+	}
+
+	digest := hashDigest(blobHash)
+	if err := s.filesystemHandler.Rename(tmpPath, filepath.Join(blobDir, digestToFilename(digest))); err != nil {
+		return imageDescriptor{}, "", err
+	}
+	return imageDescriptor{MediaType: layerMediaType, Size: counter.n, Digest: digest}, hashDigest(tarHash), nil
+}
+
+// snapshotUpperDir copies the upper dir while the container is frozen.
+// whiteouts and opaque xattrs are kept by cp -a, data blocks are shared when the filesystem supports reflink
+func (s *ContainerService) snapshotUpperDir(containerId string, diffDir string, snapshotDir string) error {
+	if err := s.filesystemHandler.RemoveAll(snapshotDir); err != nil {
+		return err
+	}
+	if err := s.freezeContainer(containerId, true); err != nil {
+		return err
+	}
+	defer s.freezeContainer(containerId, false)
+
+	cp := s.commandFactory.Command("cp", "-a", "--reflink=auto", diffDir, snapshotDir)
+	if out, err := cp.CombineOutput(); err != nil {
+		_ = s.filesystemHandler.RemoveAll(snapshotDir)
+		return fmt.Errorf("snapshot upper dir failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+func (s *ContainerService) applyCommitLayer(rootfs string, blobPath string) error {
+	f, err := s.filesystemHandler.Open(blobPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("gzip reader: %w", err)
+	}
+	defer gzr.Close()
+
+	return s.applyLayerTar(rootfs, gzr)
+}
+
+// commitImageConfig derives the new image config from the base config.
+// unknown fields of the base config are kept as is
+func (s *ContainerService) commitImageConfig(baseConfigPath string, containerId string, diffId string, commitParameter ServiceCommitModel) ([]byte, error) {
+	b, err := s.filesystemHandler.ReadFile(baseConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]any
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	// 1. runtime config: Cmd is replaced, Env is merged by key
+	runtimeConfig, _ := config["config"].(map[string]any)
+	if runtimeConfig == nil {
+		runtimeConfig = map[string]any{}
+	}
+	if len(commitParameter.Cmd) > 0 {
+		runtimeConfig["Cmd"] = commitParameter.Cmd
+	}
+	if len(commitParameter.Env) > 0 {
+		var envs []string
+		if current, ok := runtimeConfig["Env"].([]any); ok {
+			for _, e := range current {
+				if str, ok := e.(string); ok {
+					envs = append(envs, str)
+				}
+			}
+		}
+		runtimeConfig["Env"] = mergeEnv(envs, commitParameter.Env)
+	}
+	config["config"] = runtimeConfig
+
+	// 2. rootfs diff ids
+	rootfs, _ := config["rootfs"].(map[string]any)
+	if rootfs == nil {
+		rootfs = map[string]any{"type": "layers"}
+	}
+	diffIds, _ := rootfs["diff_ids"].([]any)
+	rootfs["diff_ids"] = append(diffIds, diffId)
+	config["rootfs"] = rootfs
+
+	// 3. history
+	history, _ := config["history"].([]any)
+	config["history"] = append(history, map[string]any{
+		"created":    now,
+		"created_by": "condenser commit " + containerId,
+		"comment":    commitParameter.Comment,
+	})
+	config["created"] = now
+
+	return json.Marshal(config)
+}
+
+// mergeEnv overrides KEY=VALUE entries of base by key and appends new keys
+func mergeEnv(base []string, changes []string) []string {
+	merged := append([]string{}, base...)
+	for _, change := range changes {
+		key, _, _ := strings.Cut(change, "=")
+		replaced := false
+		for i, e := range merged {
+			if k, _, _ := strings.Cut(e, "="); k == key {
+				merged[i] = change
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, change)
+		}
+	}
+	return merged
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func sha256Digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func hashDigest(h hash.Hash) string {
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+func digestToFilename(d string) string {
+	// sha256:abcd... -> sha256_abcd...
+	return strings.ReplaceAll(d, ":", "_")
+}
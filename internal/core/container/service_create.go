@@ -4,6 +4,7 @@ import (
 	"condenser/internal/core/image"
 	"condenser/internal/core/network"
 	"condenser/internal/runtime"
+	"condenser/internal/store/csm"
 	"condenser/internal/utils"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"strings"
 
 	"al.essio.dev/pkg/shellescape"
+	"golang.org/x/sys/unix"
 )
 
 // == service: create ==
@@ -43,6 +45,48 @@ func (s *ContainerService) Create(createParameter ServiceCreateModel) (id string
 		}
 	}()
 
+	// 1-1. validate resource limits against enabled cgroup controllers, restart policy and auto remove
+	resources, err := s.validateResources(createParameter.Resources)
+	if err != nil {
+		return "", err
+	}
+	restartPolicy, err := s.validateRestartPolicy(createParameter.RestartPolicy)
+	if err != nil {
+		return "", err
+	}
+	if createParameter.AutoRemove && restartPolicy.Name != "no" {
+		return "", fmt.Errorf("autoRemove conflicts with restart policy: %s", restartPolicy.Name)
+	}
+	//    dns: hostname, resolver and extra hosts. resolver defaults to the host resolv.conf
+	dns, err := s.resolveDns(containerId, createParameter)
+	if err != nil {
+		return "", err
+	}
+	//    tmpfs: user specified tmpfs and the writable defaults of a read-only rootfs
+	tmpfs, err := s.resolveTmpfs(createParameter.ReadOnlyRootfs, createParameter.Tmpfs, createParameter.Mount)
+	if err != nil {
+		return "", err
+	}
+	//    capabilities: default set with capAdd/capDrop applied
+	capabilities, err := s.resolveCapabilities(createParameter.CapAdd, createParameter.CapDrop)
+	if err != nil {
+		return "", err
+	}
+	//    seccomp: registered profile, raind-default when not specified
+	seccompProfile, seccompPath, err := s.resolveSeccompProfile(createParameter.SeccompProfile)
+	if err != nil {
+		return "", err
+	}
+	//    apparmor: loaded profile, raind-default when not specified
+	appArmorProfile, err := s.resolveAppArmorProfile(createParameter.AppArmorProfile)
+	if err != nil {
+		return "", err
+	}
+	//    runtime: reject the requested options the installed droplet does not support
+	if err := s.checkSpecOptions(createParameter); err != nil {
+		return "", err
+	}
+
 	// 2. check if the requested image exist
 	imageRepo, imageRef, err := s.parseImageRef(createParameter.Image)
 	if err != nil {
@@ -66,6 +110,40 @@ func (s *ContainerService) Create(createParameter ServiceCreateModel) (id string
 		return "", err
 	}
 
+	//    healthcheck: image config healthcheck overridden by user specified values
+	healthcheck, err := s.resolveHealthcheck(createParameter.Healthcheck, imageConfig.Config.Healthcheck)
+	if err != nil {
+		return "", err
+	}
+	//    stop signal: image config stop signal, default SIGTERM
+	stopSignal := defaultStopSignal
+	if imageConfig.Config.StopSignal != "" {
+		sig, err := parseSignal(imageConfig.Config.StopSignal)
+		if err != nil {
+			return "", fmt.Errorf("invalid image stop signal: %w", err)
+		}
+		stopSignal = unix.SignalName(sig)
+	}
+	//    labels: image config labels overridden by user specified labels
+	labels, err := s.resolveLabels(imageConfig.Config.Labels, createParameter.Labels)
+	if err != nil {
+		return "", err
+	}
+	//    process: command, user and working directory from image config and overrides
+	imageRootfs, err := s.ilmHandler.GetRootfsPath(imageRepo, imageRef)
+	if err != nil {
+		return "", err
+	}
+	process, err := s.resolveProcessConfig(createParameter, imageConfig.Config, imageRootfs)
+	if err != nil {
+		return "", err
+	}
+	if process.User != "" && process.User != rootUser {
+		if err := s.requireSpecOption("--user"); err != nil {
+			return "", err
+		}
+	}
+
 	// 5. allocate address
 	bridgeInterface := createParameter.Network
 	if bridgeInterface == "" {
@@ -78,14 +156,39 @@ func (s *ContainerService) Create(createParameter ServiceCreateModel) (id string
 	rollbackFlag.AllocateAddr = true
 
 	// 6. create CSM entry with state=creating, pid=0, creatingAt=nil
-	//    command=if user specified, use it. if not, use image config's command
-	var command []string
-	if len(createParameter.Command) > 0 {
-		command = createParameter.Command
-	} else {
-		command = slices.Concat(imageConfig.Config.Entrypoint, imageConfig.Config.Cmd)
-	}
-	if err := s.csmHandler.StoreContainer(containerId, "creating", 0, createParameter.Tty, imageRepo, imageRef, command, containerName); err != nil {
+	//    all resolved settings are stored in one write, so readers never see a partially filled entry
+	if err := s.csmHandler.StoreContainer(csm.ContainerInfo{
+		ContainerId:   containerId,
+		ContainerName: containerName,
+		State:         "creating",
+		Pid:           0,
+		Tty:           createParameter.Tty,
+		Repository:    imageRepo,
+		Reference:     imageRef,
+		Command:       process.Command,
+
+		User:         process.User,
+		ExposedPorts: exposedPorts(imageConfig.Config),
+		Labels:       labels,
+		Dns:          dns,
+
+		ReadOnlyRootfs: createParameter.ReadOnlyRootfs,
+		Tmpfs:          tmpfs,
+
+		Capabilities:    capabilities,
+		NoNewPrivileges: createParameter.NoNewPrivileges,
+		SeccompProfile:  seccompProfile,
+		AppArmorProfile: appArmorProfile,
+
+		Resources: toCsmResources(resources),
+		RestartPolicy: csm.RestartPolicyInfo{
+			Name:       restartPolicy.Name,
+			MaxRetries: restartPolicy.MaxRetries,
+		},
+		Healthcheck: healthcheck,
+		StopSignal:  stopSignal,
+		AutoRemove:  createParameter.AutoRemove,
+	}); err != nil {
 		return "", err
 	}
 	rollbackFlag.CSMEntry = true
@@ -97,7 +200,7 @@ func (s *ContainerService) Create(createParameter ServiceCreateModel) (id string
 	rollbackFlag.DirectoryEnv = true
 
 	// 8. setup etc files
-	if err := s.setupEtcFiles(containerId, containerAddr); err != nil {
+	if err := s.setupEtcFiles(containerId, containerAddr, dns); err != nil {
 		return "", fmt.Errorf("setup etc files failed: %w", err)
 	}
 
@@ -107,9 +210,22 @@ func (s *ContainerService) Create(createParameter ServiceCreateModel) (id string
 	}
 	rollbackFlag.CgroupEntry = true
 
+	// 9-1. apply resource limits before the container process joins the cgroup
+	if err := s.applyCgroupResources(containerId, resources); err != nil {
+		return "", fmt.Errorf("apply resource limits failed: %w", err)
+	}
+
+	// 9-2. attach named volumes. volume names in mount sources are replaced with the volume mountpoints
+	rollbackFlag.VolumeRef = true
+	mounts, err := s.attachNamedVolumes(containerId, imageRootfs, createParameter.Mount)
+	if err != nil {
+		return "", fmt.Errorf("attach volume failed: %w", err)
+	}
+	createParameter.Mount = mounts
+
 	// 10. create spec (config.json)
 	if err := s.createContainerSpec(
-		containerId, createParameter, imageRepo, imageRef, imageConfig,
+		containerId, createParameter, imageRootfs, imageConfig, process, dns, tmpfs, capabilities, seccompPath, appArmorProfile,
 		bridgeInterface, containerAddr, containerGateway,
 	); err != nil {
 		return "", fmt.Errorf("create spec failed: %w", err)
@@ -135,6 +251,7 @@ type RollbackFlag struct {
 	DirectoryEnv bool
 	CgroupEntry  bool
 	ForwardRule  bool
+	VolumeRef    bool
 }
 
 func (s *ContainerService) rollback(rollbackFlag RollbackFlag, containerId string) error {
@@ -163,9 +280,33 @@ func (s *ContainerService) rollback(rollbackFlag RollbackFlag, containerId strin
 			return err
 		}
 	}
+	if rollbackFlag.VolumeRef {
+		if err := s.releaseVolumes(containerId); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (s *ContainerService) validateRestartPolicy(policy RestartPolicyModel) (RestartPolicyModel, error) {
+	switch policy.Name {
+	case "", "no":
+		return RestartPolicyModel{Name: "no"}, nil
+	case "always", "unless-stopped":
+		if policy.MaxRetries != 0 {
+			return RestartPolicyModel{}, fmt.Errorf("maxRetries is only allowed with restart policy: on-failure")
+		}
+		return RestartPolicyModel{Name: policy.Name}, nil
+	case "on-failure":
+		if policy.MaxRetries < 0 {
+			return RestartPolicyModel{}, fmt.Errorf("invalid maxRetries: %d", policy.MaxRetries)
+		}
+		return policy, nil
+	default:
+		return RestartPolicyModel{}, fmt.Errorf("invalid restart policy: %s", policy.Name)
+	}
+}
+
 func (s *ContainerService) generateContainerName() (string, error) {
 	genCount := 0
 	for {
@@ -227,26 +368,26 @@ func (s *ContainerService) setupContainerDirectory(containerId string) error {
 	return nil
 }
 
-func (s *ContainerService) setupEtcFiles(containerId string, containerAddr string) error {
+func (s *ContainerService) setupEtcFiles(containerId string, containerAddr string, dns csm.DnsInfo) error {
 	etcDir := filepath.Join(utils.ContainerRootDir, containerId, "etc")
 
 	// /etc/hosts
 	hostsPath := filepath.Join(etcDir, "hosts")
-	hostsData := fmt.Sprintf("127.0.0.1 localhost\n%s %s\n", strings.SplitN(containerAddr, "/", 2)[0], containerId)
+	hostsData := buildHostsFile(dns, containerAddr)
 	if err := s.filesystemHandler.WriteFile(hostsPath, []byte(hostsData), 0o644); err != nil {
 		return err
 	}
 
 	// /etc/hostname
 	hostnamePath := filepath.Join(etcDir, "hostname")
-	hostnameData := fmt.Sprintf("%s\n", containerId)
+	hostnameData := fmt.Sprintf("%s\n", dns.Hostname)
 	if err := s.filesystemHandler.WriteFile(hostnamePath, []byte(hostnameData), 0o644); err != nil {
 		return err
 	}
 
 	// /etc/resolv.conf
 	resolvPath := filepath.Join(etcDir, "resolv.conf")
-	resolvData := "nameserver 8.8.8.8\n"
+	resolvData := buildResolvConf(dns)
 	if err := s.filesystemHandler.WriteFile(resolvPath, []byte(resolvData), 0o644); err != nil {
 		return err
 	}
@@ -290,33 +431,37 @@ func (s *ContainerService) allocateAddress(containerId string, bridgeInterface s
 
 func (s *ContainerService) createContainerSpec(
 	containerId string, createParameter ServiceCreateModel,
-	imageRepo, imageRef string, imageConfig image.ImageConfigFile,
+	imageLayer string, imageConfig image.ImageConfigFile, process processConfig, dns csm.DnsInfo, tmpfs []csm.TmpfsInfo, capabilities []string, seccompPath string, appArmorProfile string,
 	bridge, containerAddr, containerGateway string,
 ) error {
 
+	// apparmor: droplet applies raind-default unless the profile is requested
+	specAppArmorProfile := ""
+	if createParameter.AppArmorProfile != "" {
+		specAppArmorProfile = appArmorProfile
+	}
+
 	// spec parametr
 	// rootfs
 	rootfs := filepath.Join(utils.ContainerRootDir, containerId, "merged")
 
 	// cwd
-	cwd := imageConfig.Config.WorkingDir
-	if cwd == "" {
-		cwd = "/"
+	cwd := process.Cwd
+
+	// user: droplet runs the process as root by default
+	specUser := process.User
+	if specUser == rootUser {
+		specUser = ""
 	}
 
 	// command
-	var cmd string
-	if len(createParameter.Command) != 0 {
-		cmd = s.buildCommand(createParameter.Command, []string{})
-	} else {
-		cmd = s.buildCommand(imageConfig.Config.Entrypoint, imageConfig.Config.Cmd)
-	}
+	cmd := s.buildCommand(process.Command, []string{})
 
 	// namespace
 	namespace := []string{"mount", "network", "uts", "pid", "ipc", "user", "cgroup"}
 
 	// hostname
-	hostname := containerId
+	hostname := dns.Hostname
 
 	// env
 	// image predefined env
@@ -327,7 +472,22 @@ func (s *ContainerService) createContainerSpec(
 	}
 
 	// mount
-	mount := createParameter.Mount
+	// user specified mounts and anonymous volumes declared in the image. tmpfs targets are not covered by volumes
+	var tmpfsSpecs []string
+	covered := slices.Clone(createParameter.Mount)
+	for _, t := range tmpfs {
+		tmpfsSpecs = append(tmpfsSpecs, tmpfsSpec(t))
+		covered = append(covered, "tmpfs:"+t.Target)
+	}
+	anonymousVolumes, err := s.setupAnonymousVolumes(containerId, imageLayer, imageConfig.Config.Volumes, covered)
+	if err != nil {
+		return err
+	}
+	mount := slices.Concat(createParameter.Mount, anonymousVolumes)
+	// etc files stay writable on a read-only rootfs
+	if createParameter.ReadOnlyRootfs {
+		mount = slices.Concat(mount, etcFileMounts(containerId))
+	}
 
 	// host interface
 	hostInterface, err := s.ipamHandler.GetDefaultInterface()
@@ -337,12 +497,8 @@ func (s *ContainerService) createContainerSpec(
 
 	// container interface
 	containerInterface := "rd_" + containerId
-	containerDns := []string{"8.8.8.8"}
+	containerDns := dns.Servers
 
-	imageLayer, err := s.ilmHandler.GetRootfsPath(imageRepo, imageRef)
-	if err != nil {
-		return err
-	}
 	upperDir := filepath.Join(utils.ContainerRootDir, containerId, "diff")
 	workDir := filepath.Join(utils.ContainerRootDir, containerId, "work")
 	outputDir := filepath.Join(utils.ContainerRootDir, containerId)
@@ -432,10 +588,17 @@ func (s *ContainerService) createContainerSpec(
 		Rootfs:                 rootfs,
 		Cwd:                    cwd,
 		Command:                cmd,
+		User:                   specUser,
 		Namespace:              namespace,
 		Hostname:               hostname,
 		Env:                    envs,
 		Mount:                  mount,
+		Tmpfs:                  tmpfsSpecs,
+		ReadOnlyRootfs:         createParameter.ReadOnlyRootfs,
+		Capabilities:           capabilities,
+		NoNewPrivileges:        createParameter.NoNewPrivileges,
+		SeccompProfile:         seccompPath,
+		AppArmorProfile:        specAppArmorProfile,
 		HostInterface:          hostInterface,
 		BridgeInterface:        bridge,
 		ContainerInterface:     containerInterface,
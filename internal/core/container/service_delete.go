@@ -17,6 +17,13 @@ func (s *ContainerService) Delete(deleteParameter ServiceDeleteModel) (string, e
 		return "", fmt.Errorf("container: %s not found", deleteParameter.ContainerId)
 	}
 
+	// wait for the temporary rootfs mount of archive and export before removing the directory
+	unlock, err := s.lockContainer(containerId)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
 	containerState, err := s.getContainerState(containerId)
 	if err != nil {
 		return "", err
@@ -48,6 +55,13 @@ func (s *ContainerService) Delete(deleteParameter ServiceDeleteModel) (string, e
 		if err := s.deleteCgroupSubtree(containerId); err != nil {
 			return "", fmt.Errorf("delete cgroup subtree failed: %w", err)
 		}
+
+		// 5. release volume references
+		if err := s.releaseVolumes(containerId); err != nil {
+			return "", fmt.Errorf("release volumes failed: %w", err)
+		}
+	case "paused":
+		return "", fmt.Errorf("container: %s is paused. unpause and stop it before delete", containerId)
 	default:
 		return "", fmt.Errorf("delete operation not allowed to current container status: %s", containerState)
 	}
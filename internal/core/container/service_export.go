@@ -0,0 +1,83 @@
+package container
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+)
+
+// == service: export ==
+func (s *ContainerService) Export(exportParameter ServiceExportModel) (io.ReadCloser, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(exportParameter.ContainerId)
+	if err != nil {
+		return nil, fmt.Errorf("container: %s not found", exportParameter.ContainerId)
+	}
+
+	// 1. open container rootfs
+	rootfs, err := s.openContainerRootfs(containerId)
+	if err != nil {
+		return nil, err
+	}
+	rootInfo, err := rootfs.root.Stat(".")
+	if err != nil {
+		rootfs.release()
+		return nil, err
+	}
+	rootDev := deviceOf(rootInfo)
+
+	// 2. stream tar of the whole rootfs
+	pr, pw := io.Pipe()
+	go func() {
+		defer rootfs.release()
+
+		var (
+			w   io.Writer = pw
+			gzw *gzip.Writer
+		)
+		if exportParameter.Gzip {
+			gzw = gzip.NewWriter(pw)
+			w = gzw
+		}
+		tw := s.newTarWriter(w, rootfs.root, rootfs.toContainer)
+		err := rootfs.root.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == "." {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if err := tw.writeEntry(path, path, info); err != nil {
+				return err
+			}
+			// filesystems mounted in the container (proc, sys, volumes) are not exported.
+			// the mount point directory itself is kept
+			if info.IsDir() && deviceOf(info) != rootDev {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		if err == nil && gzw != nil {
+			err = gzw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+func deviceOf(info fs.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Dev
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package container
+
+import (
+	"condenser/internal/runtime"
+	"condenser/internal/store/csm"
+	"fmt"
+	"time"
+)
+
+// == service: healthcheck ==
+func (s *ContainerService) Healthcheck(healthcheckParameter ServiceHealthcheckModel) (HealthModel, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(healthcheckParameter.ContainerId)
+	if err != nil {
+		return HealthModel{}, fmt.Errorf("container: %s not found", healthcheckParameter.ContainerId)
+	}
+
+	// 1. check the container is running and has healthcheck
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return HealthModel{}, err
+	}
+	if containerInfo.State != "running" {
+		return HealthModel{}, fmt.Errorf("healthcheck not allowed to current container status: %s", containerInfo.State)
+	}
+	healthcheck := containerInfo.Healthcheck
+	if len(healthcheck.Test) == 0 {
+		return HealthModel{}, fmt.Errorf("container: %s has no healthcheck", containerId)
+	}
+
+	// 2. runtime: exec probe command
+	output, probeErr := s.runtimeHandler.ExecOutput(
+		runtime.ExecModel{
+			ContainerId: containerId,
+			Entrypoint:  healthProbeCommand(healthcheck.Test),
+			Timeout:     healthcheck.Timeout,
+		},
+	)
+	if len(output) > maxHealthOutputBytes {
+		output = output[:maxHealthOutputBytes]
+	}
+	checkedAt := time.Now()
+	inStartPeriod := checkedAt.Sub(containerInfo.StartedAt) < healthcheck.StartPeriod
+
+	// 3. update health state on the latest stored value
+	//    the result is dropped if the container was restarted while probing
+	//    failures in start period are not counted until the first success
+	health, err := s.csmHandler.UpdateHealthProbe(containerId, containerInfo.StartedAt, func(health csm.HealthInfo) csm.HealthInfo {
+		if health.Status == "" {
+			health.Status = "starting"
+		}
+		health.LastOutput = string(output)
+		health.LastCheckedAt = checkedAt
+		if probeErr == nil {
+			health.Status = "healthy"
+			health.FailingStreak = 0
+			return health
+		}
+		if health.LastOutput == "" {
+			health.LastOutput = probeErr.Error()
+		}
+		if !(inStartPeriod && health.Status == "starting") {
+			health.FailingStreak++
+			if health.FailingStreak >= healthcheck.Retries {
+				health.Status = "unhealthy"
+			}
+		}
+		return health
+	})
+	if err != nil {
+		return HealthModel{}, fmt.Errorf("csm update failed: %w", err)
+	}
+
+	return fromCsmHealth(health), nil
+}
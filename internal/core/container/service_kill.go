@@ -0,0 +1,53 @@
+package container
+
+import (
+	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// == service: kill ==
+func (s *ContainerService) Kill(killParameter ServiceKillModel) (string, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(killParameter.ContainerId)
+	if err != nil {
+		return "", fmt.Errorf("container: %s not found", killParameter.ContainerId)
+	}
+
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return "", err
+	}
+
+	// default signal: SIGKILL
+	sig := syscall.SIGKILL
+	if killParameter.Signal != "" {
+		sig, err = parseSignal(killParameter.Signal)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch containerInfo.State {
+	case "running", "paused":
+		if sig == syscall.SIGKILL {
+			// SIGKILL always terminates: go through the runtime so that the stop hook updates the state
+			if err := s.signalContainer(containerId, sig); err != nil {
+				return "", fmt.Errorf("kill failed: %w", err)
+			}
+			break
+		}
+		// other signals may be handled by the init process. deliver it directly without running the stop hook,
+		// the monitor records the exit if the process terminates
+		if containerInfo.Pid <= 0 {
+			return "", fmt.Errorf("container: %s has no init process", containerId)
+		}
+		if err := unix.Kill(containerInfo.Pid, sig); err != nil {
+			return "", fmt.Errorf("kill failed: send %s: %w", unix.SignalName(sig), err)
+		}
+	default:
+		return "", fmt.Errorf("kill operation not allowed to current container status: %s", containerInfo.State)
+	}
+	return containerId, nil
+}
@@ -1,11 +1,28 @@
 package container
 
+import (
+	"condenser/internal/store/csm"
+	"slices"
+	"strings"
+)
+
 // == service: get container list ==
-func (s *ContainerService) GetContainerList() ([]ContainerState, error) {
+func (s *ContainerService) GetContainerList(listParameter ServiceListModel) ([]ContainerState, error) {
 	containerList, err := s.csmHandler.GetContainerList()
 	if err != nil {
 		return nil, err
 	}
+	var filterRepo, filterRef string
+	if listParameter.Image != "" {
+		filterRepo, filterRef, err = s.parseImageRef(listParameter.Image)
+		if err != nil {
+			return nil, err
+		}
+		//    reference is compared only when specified
+		if !strings.ContainsAny(listParameter.Image, ":@") {
+			filterRef = ""
+		}
+	}
 	poolList, err := s.ipamHandler.GetPoolList()
 	if err != nil {
 		return nil, err
@@ -13,6 +30,10 @@ func (s *ContainerService) GetContainerList() ([]ContainerState, error) {
 
 	var containerStateList []ContainerState
 	for _, c := range containerList {
+		if !matchListFilter(c, listParameter, filterRepo, filterRef) {
+			continue
+		}
+
 		var (
 			forwards []ForwardInfo
 			address  string
@@ -42,9 +63,39 @@ func (s *ContainerService) GetContainerList() ([]ContainerState, error) {
 			Reference:   c.Reference,
 			Command:     c.Command,
 
+			User:         c.User,
+			ExposedPorts: c.ExposedPorts,
+			Labels:       c.Labels,
+			Dns:          fromCsmDns(c.Dns),
+
+			ReadOnlyRootfs: c.ReadOnlyRootfs,
+			Tmpfs:          fromCsmTmpfs(c.Tmpfs),
+
+			Capabilities:    c.Capabilities,
+			NoNewPrivileges: c.NoNewPrivileges,
+			SeccompProfile:  c.SeccompProfile,
+			AppArmorProfile: c.AppArmorProfile,
+
 			Address:  address,
 			Forwards: forwards,
 
+			Resources: fromCsmResources(c.Resources),
+			RestartPolicy: RestartPolicyModel{
+				Name:       c.RestartPolicy.Name,
+				MaxRetries: c.RestartPolicy.MaxRetries,
+			},
+			RestartCount: c.RestartCount,
+			Healthcheck:  fromCsmHealthcheck(c.Healthcheck),
+			Health:       fromCsmHealth(c.Health),
+			StopSignal:   c.StopSignal,
+			AutoRemove:   c.AutoRemove,
+
+			ExitCode:       c.ExitCode,
+			FinishedReason: c.FinishedReason,
+			ExitSignal:     c.ExitSignal,
+			OomKilled:      c.OomKilled,
+
+			Created:    c.Created,
 			CreatingAt: c.CreatingAt,
 			CreatedAt:  c.CreatedAt,
 			StartedAt:  c.StartedAt,
@@ -55,6 +106,33 @@ func (s *ContainerService) GetContainerList() ([]ContainerState, error) {
 	return containerStateList, nil
 }
 
+func matchListFilter(c csm.ContainerInfo, f ServiceListModel, repo, ref string) bool {
+	if !MatchLabels(c.Labels, f.Labels) {
+		return false
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, c.State) {
+		return false
+	}
+	if repo != "" && (c.Repository != repo || (ref != "" && c.Reference != ref)) {
+		return false
+	}
+	if !strings.HasPrefix(c.ContainerName, f.NamePrefix) {
+		return false
+	}
+	// entries stored before the creation time was recorded fall back to creatingAt
+	created := c.Created
+	if created.IsZero() {
+		created = c.CreatingAt
+	}
+	if !f.CreatedSince.IsZero() && created.Before(f.CreatedSince) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !created.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
 // =================================
 
 // == service: get container by id ==
@@ -85,9 +163,39 @@ func (s *ContainerService) GetContainerById(containerId string) (ContainerState,
 		Reference:   containerState.Reference,
 		Command:     containerState.Command,
 
+		User:         containerState.User,
+		ExposedPorts: containerState.ExposedPorts,
+		Labels:       containerState.Labels,
+		Dns:          fromCsmDns(containerState.Dns),
+
+		ReadOnlyRootfs: containerState.ReadOnlyRootfs,
+		Tmpfs:          fromCsmTmpfs(containerState.Tmpfs),
+
+		Capabilities:    containerState.Capabilities,
+		NoNewPrivileges: containerState.NoNewPrivileges,
+		SeccompProfile:  containerState.SeccompProfile,
+		AppArmorProfile: containerState.AppArmorProfile,
+
 		Address:  address,
 		Forwards: forwards,
 
+		Resources: fromCsmResources(containerState.Resources),
+		RestartPolicy: RestartPolicyModel{
+			Name:       containerState.RestartPolicy.Name,
+			MaxRetries: containerState.RestartPolicy.MaxRetries,
+		},
+		RestartCount: containerState.RestartCount,
+		Healthcheck:  fromCsmHealthcheck(containerState.Healthcheck),
+		Health:       fromCsmHealth(containerState.Health),
+		StopSignal:   containerState.StopSignal,
+		AutoRemove:   containerState.AutoRemove,
+
+		ExitCode:       containerState.ExitCode,
+		FinishedReason: containerState.FinishedReason,
+		ExitSignal:     containerState.ExitSignal,
+		OomKilled:      containerState.OomKilled,
+
+		Created:    containerState.Created,
 		CreatingAt: containerState.CreatingAt,
 		CreatedAt:  containerState.CreatedAt,
 		StartedAt:  containerState.StartedAt,
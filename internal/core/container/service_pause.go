@@ -0,0 +1,131 @@
+package container
+
+import (
+	"condenser/internal/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// time to wait cgroup.events reports the requested freeze state
+	freezeTimeout      = 5 * time.Second
+	freezePollInterval = 10 * time.Millisecond
+)
+
+// == service: pause ==
+func (s *ContainerService) Pause(pauseParameter ServicePauseModel) (string, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(pauseParameter.ContainerId)
+	if err != nil {
+		return "", fmt.Errorf("container: %s not found", pauseParameter.ContainerId)
+	}
+
+	containerState, err := s.getContainerState(containerId)
+	if err != nil {
+		return "", err
+	}
+
+	switch containerState {
+	case "running":
+		// 1. freeze cgroup
+		if err := s.freezeContainer(containerId, true); err != nil {
+			return "", fmt.Errorf("pause failed: %w", err)
+		}
+		// 2. update CSM. pid is kept
+		if err := s.csmHandler.UpdateContainer(containerId, "paused", -1); err != nil {
+			_ = s.freezeContainer(containerId, false)
+			return "", fmt.Errorf("csm update failed: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("pause operation not allowed to current container status: %s", containerState)
+	}
+	return containerId, nil
+}
+
+// =================================
+
+// == service: unpause ==
+func (s *ContainerService) Unpause(unpauseParameter ServiceUnpauseModel) (string, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(unpauseParameter.ContainerId)
+	if err != nil {
+		return "", fmt.Errorf("container: %s not found", unpauseParameter.ContainerId)
+	}
+
+	containerState, err := s.getContainerState(containerId)
+	if err != nil {
+		return "", err
+	}
+
+	switch containerState {
+	case "paused":
+		if err := s.unpauseContainer(containerId); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unpause operation not allowed to current container status: %s", containerState)
+	}
+	return containerId, nil
+}
+
+func (s *ContainerService) unpauseContainer(containerId string) error {
+	// 1. thaw cgroup
+	if err := s.freezeContainer(containerId, false); err != nil {
+		return fmt.Errorf("unpause failed: %w", err)
+	}
+	// 2. update CSM. pid is kept
+	if err := s.csmHandler.UpdateContainer(containerId, "running", -1); err != nil {
+		return fmt.Errorf("csm update failed: %w", err)
+	}
+	return nil
+}
+
+// freezeContainer writes cgroup.freeze and waits until cgroup.events reports the state
+func (s *ContainerService) freezeContainer(containerId string, frozen bool) error {
+	cgroupPath := filepath.Join(utils.CgroupRuntimeDir, containerId)
+
+	value := "0"
+	if frozen {
+		value = "1"
+	}
+	if err := s.filesystemHandler.WriteFile(filepath.Join(cgroupPath, "cgroup.freeze"), []byte(value), 0o644); err != nil {
+		return fmt.Errorf("write cgroup.freeze failed: %w", err)
+	}
+
+	deadline := time.Now().Add(freezeTimeout)
+	for {
+		current, err := s.readFrozenState(cgroupPath)
+		if err != nil {
+			return fmt.Errorf("read cgroup.events failed: %w", err)
+		}
+		if current == frozen {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(freezePollInterval)
+	}
+
+	// rollback freeze request
+	if frozen {
+		_ = s.filesystemHandler.WriteFile(filepath.Join(cgroupPath, "cgroup.freeze"), []byte("0"), 0o644)
+	}
+	return fmt.Errorf("cgroup.events did not report frozen=%s within %s", value, freezeTimeout)
+}
+
+func (s *ContainerService) readFrozenState(cgroupPath string) (bool, error) {
+	b, err := s.filesystemHandler.ReadFile(filepath.Join(cgroupPath, "cgroup.events"))
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "frozen" {
+			return fields[1] == "1", nil
+		}
+	}
+	return false, fmt.Errorf("frozen field not found")
+}
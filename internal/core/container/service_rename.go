@@ -0,0 +1,40 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+)
+
+// == service: rename ==
+func (s *ContainerService) Rename(renameParameter ServiceRenameModel) (string, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(renameParameter.ContainerId)
+	if err != nil {
+		return "", fmt.Errorf("container: %s not found", renameParameter.ContainerId)
+	}
+
+	name := renameParameter.Name
+	if name == "" || strings.ContainsAny(name, " \t\n/") {
+		return "", fmt.Errorf("invalid name: %q", name)
+	}
+
+	// 1. update CSM. uniqueness is checked in the CSM lock
+	oldName, err := s.csmHandler.RenameContainer(containerId, name)
+	if err != nil {
+		return "", err
+	}
+	if oldName == name {
+		return containerId, nil
+	}
+
+	// 2. update network policies referring to the old name.
+	//    rules already applied are keyed by veth and keep working
+	if err := s.npmHandler.RenameContainer(oldName, name); err != nil {
+		if _, rbErr := s.csmHandler.RenameContainer(containerId, oldName); rbErr != nil {
+			return "", fmt.Errorf("rename policies failed: %w (rollback failed: %v)", err, rbErr)
+		}
+		return "", fmt.Errorf("rename policies failed: %w", err)
+	}
+
+	return containerId, nil
+}
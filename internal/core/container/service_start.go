@@ -13,6 +13,13 @@ func (s *ContainerService) Start(startParameter ServiceStartModel) (string, erro
 		return "", fmt.Errorf("container: %s not found", startParameter.ContainerId)
 	}
 
+	// the rootfs is mounted by the runtime. wait for the temporary mount of archive and export
+	unlock, err := s.lockContainer(containerId)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
 	containerState, err := s.getContainerState(containerId)
 	if err != nil {
 		return "", err
@@ -30,6 +37,10 @@ func (s *ContainerService) Start(startParameter ServiceStartModel) (string, erro
 		return "", fmt.Errorf("container: %s already started", containerId)
 
 	case "stopped":
+		// restore cgroup subtree if it was removed (e.g. host reboot)
+		if err := s.restoreCgroupSubtree(containerId); err != nil {
+			return "", fmt.Errorf("restore cgroup subtree failed: %w", err)
+		}
 		// create container
 		if err := s.createContainer(containerId, startParameter.Tty); err != nil {
 			return "", fmt.Errorf("start container failed: %w", err)
@@ -43,6 +54,15 @@ func (s *ContainerService) Start(startParameter ServiceStartModel) (string, erro
 		return "", fmt.Errorf("start operation not allowed to current container status: %s", containerState)
 	}
 
+	// clear manual stop flag so that restart policy is applied again
+	if err := s.csmHandler.UpdateManuallyStopped(containerId, false); err != nil {
+		return "", fmt.Errorf("csm update failed: %w", err)
+	}
+	// reset health state. probe result of previous run is discarded
+	if err := s.resetHealth(containerId); err != nil {
+		return "", fmt.Errorf("csm update failed: %w", err)
+	}
+
 	return containerId, nil
 }
 
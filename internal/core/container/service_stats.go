@@ -0,0 +1,178 @@
+package container
+
+import (
+	"condenser/internal/utils"
+	"fmt"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// == service: stats ==
+// previous is the last sample of the same container. cpu percent is computed
+// between the samples, and left 0 when previous is empty
+func (s *ContainerService) GetContainerStats(target string, previous ContainerStats) (ContainerStats, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(target)
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("container: %s not found", target)
+	}
+
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return ContainerStats{}, err
+	}
+	switch containerInfo.State {
+	case "running", "paused":
+	default:
+		return ContainerStats{}, fmt.Errorf("stats not allowed to current container status: %s", containerInfo.State)
+	}
+
+	cgroupPath := filepath.Join(utils.CgroupRuntimeDir, containerId)
+	stats := ContainerStats{
+		ContainerId: containerId,
+		ReadAt:      time.Now(),
+		OnlineCpus:  goruntime.NumCPU(),
+	}
+
+	// 1. memory
+	stats.Memory.Current, err = s.readCgroupUint(filepath.Join(cgroupPath, "memory.current"))
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("read memory.current failed: %w", err)
+	}
+	stats.Memory.Max = s.readCgroupString(filepath.Join(cgroupPath, "memory.max"))
+	stats.Memory.Stat, err = s.readCgroupKeyValue(filepath.Join(cgroupPath, "memory.stat"))
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("read memory.stat failed: %w", err)
+	}
+
+	// 2. cpu
+	cpuStat, err := s.readCgroupKeyValue(filepath.Join(cgroupPath, "cpu.stat"))
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("read cpu.stat failed: %w", err)
+	}
+	stats.Cpu = CpuStats{
+		UsageUsec:     cpuStat["usage_usec"],
+		UserUsec:      cpuStat["user_usec"],
+		SystemUsec:    cpuStat["system_usec"],
+		NrPeriods:     cpuStat["nr_periods"],
+		NrThrottled:   cpuStat["nr_throttled"],
+		ThrottledUsec: cpuStat["throttled_usec"],
+	}
+	//    percent of one cpu: 200% means two cpus are fully used
+	if !previous.ReadAt.IsZero() && stats.Cpu.UsageUsec >= previous.Cpu.UsageUsec {
+		wall := stats.ReadAt.Sub(previous.ReadAt).Microseconds()
+		if wall > 0 {
+			stats.Cpu.Percent = float64(stats.Cpu.UsageUsec-previous.Cpu.UsageUsec) / float64(wall) * 100
+		}
+	}
+
+	// 3. io (io controller may not be enabled)
+	stats.Io = s.readCgroupIoStat(filepath.Join(cgroupPath, "io.stat"))
+
+	// 4. pids
+	stats.Pids.Current, err = s.readCgroupUint(filepath.Join(cgroupPath, "pids.current"))
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("read pids.current failed: %w", err)
+	}
+	stats.Pids.Max = s.readCgroupString(filepath.Join(cgroupPath, "pids.max"))
+
+	// 5. network
+	//    counters of host side veth are reversed: host rx is container tx
+	veth := "rd_" + containerId
+	netDir := filepath.Join("/sys/class/net", veth, "statistics")
+	read := func(name string) uint64 {
+		v, _ := s.readCgroupUint(filepath.Join(netDir, name))
+		return v
+	}
+	stats.Network = NetworkStats{
+		Interface: veth,
+		RxBytes:   read("tx_bytes"),
+		RxPackets: read("tx_packets"),
+		RxErrors:  read("tx_errors"),
+		RxDropped: read("tx_dropped"),
+		TxBytes:   read("rx_bytes"),
+		TxPackets: read("rx_packets"),
+		TxErrors:  read("rx_errors"),
+		TxDropped: read("rx_dropped"),
+	}
+
+	return stats, nil
+}
+
+func (s *ContainerService) readCgroupUint(path string) (uint64, error) {
+	b, err := s.filesystemHandler.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
+
+func (s *ContainerService) readCgroupString(path string) string {
+	b, err := s.filesystemHandler.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// readCgroupKeyValue parses flat keyed file (e.g. memory.stat, cpu.stat)
+func (s *ContainerService) readCgroupKeyValue(path string) (map[string]uint64, error) {
+	b, err := s.filesystemHandler.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	kv := map[string]uint64{}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		kv[fields[0]] = v
+	}
+	return kv, nil
+}
+
+// readCgroupIoStat parses nested keyed io.stat: "$MAJ:$MIN rbytes=N wbytes=N rios=N wios=N ..."
+func (s *ContainerService) readCgroupIoStat(path string) []IoStats {
+	b, err := s.filesystemHandler.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var ioStats []IoStats
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		st := IoStats{Device: fields[0]}
+		for _, f := range fields[1:] {
+			k, v, ok := strings.Cut(f, "=")
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch k {
+			case "rbytes":
+				st.ReadBytes = n
+			case "wbytes":
+				st.WriteBytes = n
+			case "rios":
+				st.ReadIos = n
+			case "wios":
+				st.WriteIos = n
+			}
+		}
+		ioStats = append(ioStats, st)
+	}
+	return ioStats
+}
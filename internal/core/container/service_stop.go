@@ -2,7 +2,23 @@ package container
 
 import (
 	"condenser/internal/runtime"
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	defaultStopSignal  = "SIGTERM"
+	defaultStopTimeout = 10 * time.Second
+	// time to wait the monitor observes exit after SIGKILL
+	killWaitTimeout = 10 * time.Second
 )
 
 // == service: stop ==
@@ -13,31 +29,159 @@ func (s *ContainerService) Stop(stopParameter ServiceStopModel) (string, error)
 		return "", fmt.Errorf("container: %s not found", stopParameter.ContainerId)
 	}
 
-	containerState, err := s.getContainerState(containerId)
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return "", err
+	}
+
+	// resolve stop signal and timeout
+	//   signal: request > image StopSignal > SIGTERM
+	signalName := stopParameter.Signal
+	if signalName == "" {
+		signalName = containerInfo.StopSignal
+	}
+	if signalName == "" {
+		signalName = defaultStopSignal
+	}
+	stopSignal, err := parseSignal(signalName)
 	if err != nil {
 		return "", err
 	}
+	timeout := defaultStopTimeout
+	if stopParameter.Timeout != nil {
+		if *stopParameter.Timeout < 0 {
+			return "", fmt.Errorf("invalid timeout: %d", *stopParameter.Timeout)
+		}
+		timeout = time.Duration(*stopParameter.Timeout) * time.Second
+	}
 
-	switch containerState {
+	switch containerInfo.State {
+	case "paused":
+		// frozen processes cannot handle the stop signal. thaw before stopping
+		if err := s.unpauseContainer(containerId); err != nil {
+			return "", err
+		}
+		fallthrough
 	case "running":
+		// mark as manually stopped so that the monitor does not restart the container
+		if err := s.csmHandler.UpdateManuallyStopped(containerId, true); err != nil {
+			return "", fmt.Errorf("csm update failed: %w", err)
+		}
 		// stop container
-		if err := s.stopContainer(containerId); err != nil {
+		if err := s.stopContainer(containerId, stopSignal, timeout); err != nil {
+			_ = s.csmHandler.UpdateManuallyStopped(containerId, false)
 			return "", fmt.Errorf("stop failed: %w", err)
 		}
 	default:
-		return "", fmt.Errorf("stop operation not allowed to current container status: %s", containerState)
+		return "", fmt.Errorf("stop operation not allowed to current container status: %s", containerInfo.State)
 	}
 	return containerId, nil
 }
 
-func (s *ContainerService) stopContainer(containerId string) error {
-	// runtime: stop
+// stopContainer sends stop signal to the init process through the runtime and waits until the monitor observes exit.
+// if the container does not exit within timeout, SIGKILL is sent
+func (s *ContainerService) stopContainer(containerId string, stopSignal syscall.Signal, timeout time.Duration) error {
+	// 1. send stop signal
+	if timeout > 0 {
+		if err := s.signalContainer(containerId, stopSignal); err != nil {
+			return err
+		}
+		stopped, err := s.waitContainerStopped(containerId, timeout)
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+
+	// 2. escalate to SIGKILL
+	if err := s.signalContainer(containerId, syscall.SIGKILL); err != nil {
+		return err
+	}
+	stopped, err := s.waitContainerStopped(containerId, killWaitTimeout)
+	if err != nil {
+		return err
+	}
+	if !stopped {
+		return fmt.Errorf("container: %s did not exit after SIGKILL", containerId)
+	}
+	return nil
+}
+
+// waitContainerStopped waits on CSM updates until the monitor records the exit.
+// the stop hook changes the state to stopped before the process exits, so pid is cleared only by the monitor
+func (s *ContainerService) waitContainerStopped(containerId string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// subscribe before the first check so that no update is missed
+	notify, err := csm.Watch(ctx, utils.CsmStorePath)
+	if err != nil {
+		return false, fmt.Errorf("watch csm failed: %w", err)
+	}
+	for {
+		containerInfo, err := s.csmHandler.GetContainerById(containerId)
+		if err != nil || (containerInfo.State == "stopped" && containerInfo.Pid <= 0) {
+			return true, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-notify:
+		}
+	}
+}
+
+// signalContainer delivers the signal through the runtime, so that the runtime state and stop hooks are kept consistent.
+// droplet without kill --signal sends only SIGTERM, other signals are sent to the init process directly
+func (s *ContainerService) signalContainer(containerId string, sig syscall.Signal) error {
+	if sig != syscall.SIGTERM {
+		supported, err := s.runtimeHandler.SupportsOption("kill", "--signal")
+		if err != nil {
+			return err
+		}
+		if !supported {
+			containerInfo, err := s.csmHandler.GetContainerById(containerId)
+			if err != nil {
+				return err
+			}
+			if containerInfo.Pid <= 0 {
+				return fmt.Errorf("container: %s has no init process", containerId)
+			}
+			if err := unix.Kill(containerInfo.Pid, sig); err != nil {
+				return fmt.Errorf("send %s failed: %w", unix.SignalName(sig), err)
+			}
+			return nil
+		}
+	}
 	if err := s.runtimeHandler.Stop(
 		runtime.StopModel{
 			ContainerId: containerId,
+			Signal:      unix.SignalName(sig),
 		},
 	); err != nil {
-		return err
+		return fmt.Errorf("send %s failed: %w", unix.SignalName(sig), err)
 	}
 	return nil
 }
+
+// parseSignal accepts "SIGTERM", "TERM" or "15" style signal
+func parseSignal(v string) (syscall.Signal, error) {
+	v = strings.TrimSpace(v)
+	if n, err := strconv.Atoi(v); err == nil {
+		if unix.SignalName(syscall.Signal(n)) == "" {
+			return 0, fmt.Errorf("unknown signal: %s", v)
+		}
+		return syscall.Signal(n), nil
+	}
+	name := strings.ToUpper(v)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return 0, fmt.Errorf("unknown signal: %s", v)
+	}
+	return sig, nil
+}
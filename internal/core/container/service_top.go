@@ -0,0 +1,231 @@
+package container
+
+import (
+	"condenser/internal/utils"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// USER_HZ used by /proc/<pid>/stat
+const clockTicks = 100
+
+// upper limit of /etc/passwd read from the container
+const maxPasswdSize = 1 << 20
+
+// == service: top ==
+func (s *ContainerService) GetContainerTop(target string) (ContainerTop, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(target)
+	if err != nil {
+		return ContainerTop{}, fmt.Errorf("container: %s not found", target)
+	}
+
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return ContainerTop{}, err
+	}
+	switch containerInfo.State {
+	case "running", "paused":
+	default:
+		return ContainerTop{}, fmt.Errorf("top not allowed to current container status: %s", containerInfo.State)
+	}
+
+	// 1. enumerate pids in the container cgroup subtree
+	hostPids, err := s.readCgroupProcs(filepath.Join(utils.CgroupRuntimeDir, containerId))
+	if err != nil {
+		return ContainerTop{}, fmt.Errorf("read cgroup.procs failed: %w", err)
+	}
+
+	// 2. read /proc of each process
+	//    processes may exit while reading. they are skipped
+	users := s.readContainerUsers(containerId, containerInfo.Pid)
+	var procs []procStatus
+	for _, pid := range hostPids {
+		p, err := s.readProcStatus(pid)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, p)
+	}
+
+	// 3. map pids into the container pid namespace
+	nsPid := map[int]int{}
+	for _, p := range procs {
+		nsPid[p.hostPid] = p.nsPid
+	}
+	processes := []ProcessInfo{}
+	for _, p := range procs {
+		uid := s.readIdMap(p.hostPid, "uid_map").toContainer(p.uid)
+		user := strconv.Itoa(uid)
+		if name, ok := users[uid]; ok {
+			user = name
+		}
+		processes = append(processes, ProcessInfo{
+			Pid:     p.nsPid,
+			HostPid: p.hostPid,
+			// parent outside the container (runtime) is reported as 0
+			Ppid:    nsPid[p.hostPpid],
+			User:    user,
+			Uid:     uid,
+			State:   p.state,
+			Rss:     p.rssKb * 1024,
+			CpuTime: p.cpuTime.String(),
+			Command: p.command,
+		})
+	}
+	slices.SortFunc(processes, func(a, b ProcessInfo) int { return a.Pid - b.Pid })
+
+	return ContainerTop{
+		ContainerId: containerId,
+		Processes:   processes,
+	}, nil
+}
+
+type procStatus struct {
+	hostPid  int
+	hostPpid int
+	nsPid    int
+	uid      int
+	state    string
+	rssKb    uint64
+	cpuTime  time.Duration
+	command  string
+}
+
+func (s *ContainerService) readCgroupProcs(cgroupPath string) ([]int, error) {
+	var pids []int
+	err := s.filesystemHandler.WalkDir(cgroupPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		b, err := s.filesystemHandler.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			return nil
+		}
+		for _, f := range strings.Fields(string(b)) {
+			if pid, err := strconv.Atoi(f); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+		return nil
+	})
+	return pids, err
+}
+
+func (s *ContainerService) readProcStatus(pid int) (procStatus, error) {
+	procDir := filepath.Join("/proc", strconv.Itoa(pid))
+	p := procStatus{hostPid: pid, nsPid: pid}
+
+	// status: PPid, Uid, State, VmRSS, NSpid
+	b, err := s.filesystemHandler.ReadFile(filepath.Join(procDir, "status"))
+	if err != nil {
+		return procStatus{}, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "PPid":
+			p.hostPpid, _ = strconv.Atoi(fields[0])
+		case "Uid":
+			// real uid
+			p.uid, _ = strconv.Atoi(fields[0])
+		case "State":
+			p.state = fields[0]
+		case "VmRSS":
+			p.rssKb, _ = strconv.ParseUint(fields[0], 10, 64)
+		case "NSpid":
+			// the last field is the pid in the innermost pid namespace
+			p.nsPid, _ = strconv.Atoi(fields[len(fields)-1])
+		}
+	}
+
+	// stat: utime(14), stime(15)
+	//   comm (2) may contain spaces, so fields are counted after the last ')'
+	b, err = s.filesystemHandler.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return procStatus{}, err
+	}
+	stat := string(b)
+	if i := strings.LastIndex(stat, ")"); i >= 0 {
+		fields := strings.Fields(stat[i+1:])
+		if len(fields) > 12 {
+			utime, _ := strconv.ParseUint(fields[11], 10, 64)
+			stime, _ := strconv.ParseUint(fields[12], 10, 64)
+			p.cpuTime = time.Duration(utime+stime) * time.Second / clockTicks
+		}
+	}
+
+	// cmdline: NUL separated. kernel threads have empty cmdline
+	b, err = s.filesystemHandler.ReadFile(filepath.Join(procDir, "cmdline"))
+	if err != nil {
+		return procStatus{}, err
+	}
+	p.command = strings.TrimSpace(strings.ReplaceAll(string(b), "\x00", " "))
+	if p.command == "" {
+		if comm, err := s.filesystemHandler.ReadFile(filepath.Join(procDir, "comm")); err == nil {
+			p.command = "[" + strings.TrimSpace(string(comm)) + "]"
+		}
+	}
+	return p, nil
+}
+
+// readContainerUsers reads user names from /etc/passwd in the container rootfs.
+// the file is controlled by the container: it is resolved in the rootfs and must be a regular file
+func (s *ContainerService) readContainerUsers(containerId string, pid int) map[int]string {
+	users := map[int]string{}
+	rootPath := filepath.Join(utils.ContainerRootDir, containerId, "merged")
+	if !s.filesystemHandler.IsMountPoint(rootPath) {
+		rootPath = filepath.Join("/proc", strconv.Itoa(pid), "root")
+	}
+	root, err := s.filesystemHandler.OpenRoot(rootPath)
+	if err != nil {
+		return users
+	}
+	defer root.Close()
+	// check before open: opening a device node or a fifo may block or have side effects
+	if info, err := root.Lstat("etc/passwd"); err != nil || !info.Mode().IsRegular() {
+		return users
+	}
+	f, err := root.OpenFile("etc/passwd", os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return users
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+		return users
+	}
+	b, err := io.ReadAll(io.LimitReader(f, maxPasswdSize))
+	if err != nil {
+		return users
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		users[uid] = fields[0]
+	}
+	return users
+}
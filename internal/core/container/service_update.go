@@ -0,0 +1,92 @@
+package container
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// == service: update ==
+func (s *ContainerService) Update(updateParameter ServiceUpdateModel) (string, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(updateParameter.ContainerId)
+	if err != nil {
+		return "", fmt.Errorf("container: %s not found", updateParameter.ContainerId)
+	}
+
+	// concurrent updates would interleave cgroup writes and the merge of stored limits
+	unlock, err := s.lockContainer(containerId)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	containerInfo, err := s.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return "", err
+	}
+
+	switch containerInfo.State {
+	case "created", "running", "paused":
+		// 1. validate requested limits
+		resources, err := s.validateResources(updateParameter.Resources)
+		if err != nil {
+			return "", err
+		}
+		if len(s.buildCgroupLimits(resources)) == 0 {
+			return "", fmt.Errorf("no resource limit specified")
+		}
+
+		// 2. write limit files to the live cgroup
+		if err := s.applyCgroupResources(containerId, resources); err != nil {
+			return "", fmt.Errorf("update resource limits failed: %w", err)
+		}
+
+		// 3. persist merged limits
+		merged := s.mergeResources(fromCsmResources(containerInfo.Resources), resources)
+		if err := s.csmHandler.UpdateResources(containerId, toCsmResources(merged)); err != nil {
+			return "", fmt.Errorf("csm update failed: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("update operation not allowed to current container status: %s", containerInfo.State)
+	}
+
+	return containerId, nil
+}
+
+func (s *ContainerService) mergeResources(current ResourceModel, update ResourceModel) ResourceModel {
+	merged := current
+	// the slice is shared with current
+	merged.IoMax = slices.Clone(current.IoMax)
+	if update.MemoryMax != "" {
+		merged.MemoryMax = update.MemoryMax
+	}
+	if update.MemorySwapMax != "" {
+		merged.MemorySwapMax = update.MemorySwapMax
+	}
+	if update.CpuMax != "" {
+		merged.CpuMax = update.CpuMax
+	}
+	if update.CpuWeight != 0 {
+		merged.CpuWeight = update.CpuWeight
+	}
+	if update.PidsMax != "" {
+		merged.PidsMax = update.PidsMax
+	}
+	// io.max is merged per device
+	for _, u := range update.IoMax {
+		device := strings.Fields(u)[0]
+		replaced := false
+		for i, c := range merged.IoMax {
+			if strings.Fields(c)[0] != device {
+				continue
+			}
+			merged.IoMax[i] = u
+			replaced = true
+		}
+		if !replaced {
+			merged.IoMax = append(merged.IoMax, u)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,96 @@
+package container
+
+import (
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+// server side limit of wait operation
+const maxWaitTimeout = 1 * time.Hour
+
+// == service: wait ==
+func (s *ContainerService) Wait(ctx context.Context, waitParameter ServiceWaitModel) (WaitResult, error) {
+	// resolve container id
+	containerId, err := s.csmHandler.ResolveContainerId(waitParameter.ContainerId)
+	if err != nil {
+		return WaitResult{}, fmt.Errorf("container: %s not found", waitParameter.ContainerId)
+	}
+
+	// 1. validate condition and timeout
+	condition := waitParameter.Condition
+	if condition == "" {
+		condition = "stopped"
+	}
+	switch condition {
+	case "stopped", "not-running", "removed":
+	default:
+		return WaitResult{}, fmt.Errorf("invalid condition: %s (stopped, not-running, removed)", condition)
+	}
+	timeout := maxWaitTimeout
+	if waitParameter.Timeout != 0 {
+		if waitParameter.Timeout < 0 || time.Duration(waitParameter.Timeout)*time.Second > maxWaitTimeout {
+			return WaitResult{}, fmt.Errorf("invalid timeout: %d (range: 1-%d)", waitParameter.Timeout, int(maxWaitTimeout.Seconds()))
+		}
+		timeout = time.Duration(waitParameter.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 2. subscribe CSM update before the first check so that no update is missed
+	notify, err := csm.Watch(ctx, utils.CsmStorePath)
+	if err != nil {
+		return WaitResult{}, fmt.Errorf("watch csm failed: %w", err)
+	}
+
+	// 3. check condition on every CSM update
+	//    the last seen entry is kept to report exit status after removal.
+	//    an exit recorded before the wait is accepted only on the first check
+	var last csm.ContainerInfo
+	since := time.Time{}
+	for {
+		containerInfo, err := s.csmHandler.GetContainerById(containerId)
+		removed := err != nil
+		if !removed {
+			last = containerInfo
+		}
+		if s.waitConditionMet(condition, containerInfo, removed, since) {
+			return WaitResult{
+				ContainerId:    containerId,
+				State:          last.State,
+				ExitCode:       last.ExitCode,
+				FinishedReason: last.FinishedReason,
+				OomKilled:      last.OomKilled,
+			}, nil
+		}
+
+		if since.IsZero() {
+			since = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return WaitResult{}, fmt.Errorf("wait timed out after %s: %w", timeout, ctx.Err())
+			}
+			return WaitResult{}, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// waitConditionMet checks the condition. stopped requires the exit recorded by the monitor after since:
+// the stop hook changes the state to stopped before the process exits
+func (s *ContainerService) waitConditionMet(condition string, containerInfo csm.ContainerInfo, removed bool, since time.Time) bool {
+	exited := containerInfo.State == "stopped" && containerInfo.Pid <= 0 && !containerInfo.StoppedAt.Before(since)
+	switch condition {
+	case "removed":
+		return removed
+	case "not-running":
+		return removed || containerInfo.State == "created" || exited
+	default:
+		return removed || exited
+	}
+}
@@ -0,0 +1,51 @@
+package container
+
+import (
+	"condenser/internal/lsm"
+	"fmt"
+)
+
+// uid:gid the process runs as when droplet is not given --user
+const rootUser = "0:0"
+
+// requireSpecOption returns an error when the installed droplet does not support the spec option
+func (s *ContainerService) requireSpecOption(option string) error {
+	supported, err := s.specOptionSupported(option)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("%s is not supported by the installed droplet. upgrade droplet", option)
+	}
+	return nil
+}
+
+// specOptionSupported reports whether the installed droplet supports the spec option
+func (s *ContainerService) specOptionSupported(option string) (bool, error) {
+	return s.runtimeHandler.SupportsOption("spec", option)
+}
+
+// checkSpecOptions rejects the requested options the installed droplet does not support.
+// defaults are not checked here: they are passed only when droplet supports them
+func (s *ContainerService) checkSpecOptions(createParameter ServiceCreateModel) error {
+	options := []struct {
+		option string
+		used   bool
+	}{
+		{"--tmpfs", len(createParameter.Tmpfs) > 0 || createParameter.ReadOnlyRootfs},
+		{"--read_only_rootfs", createParameter.ReadOnlyRootfs},
+		{"--cap", len(createParameter.CapAdd) > 0 || len(createParameter.CapDrop) > 0},
+		{"--no_new_privs", createParameter.NoNewPrivileges},
+		{"--seccomp", createParameter.SeccompProfile != "" && createParameter.SeccompProfile != lsm.SeccompUnconfined},
+		{"--apparmor", createParameter.AppArmorProfile != ""},
+	}
+	for _, o := range options {
+		if !o.used {
+			continue
+		}
+		if err := s.requireSpecOption(o.option); err != nil {
+			return err
+		}
+	}
+	return nil
+}
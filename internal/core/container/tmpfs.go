@@ -0,0 +1,115 @@
+package container
+
+import (
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// size: bytes with optional k/m/g suffix, or percentage of memory
+var tmpfsSizePattern = regexp.MustCompile(`^[0-9]+[kKmMgG%]?$`)
+
+// writable directories mounted as tmpfs on a read-only rootfs unless the user mounts them
+var defaultTmpfs = []TmpfsModel{
+	{Target: "/tmp", Size: "64m", Mode: "1777"},
+	{Target: "/run", Size: "16m", Mode: "755"},
+}
+
+// files written by setupEtcFiles. bind mounted to stay writable on a read-only rootfs
+var etcFiles = []string{"hosts", "hostname", "resolv.conf"}
+
+// resolveTmpfs validates tmpfs mounts and adds the defaults for a read-only rootfs
+func (s *ContainerService) resolveTmpfs(readOnlyRootfs bool, tmpfs []TmpfsModel, mounts []string) ([]csm.TmpfsInfo, error) {
+	// destinations of bind mounts and volumes
+	used := map[string]string{}
+	for _, m := range mounts {
+		parts := strings.SplitN(m, ":", 3)
+		if len(parts) >= 2 {
+			used[filepath.Clean(parts[1])] = "mount"
+		}
+	}
+
+	var resolved []csm.TmpfsInfo
+	for _, t := range tmpfs {
+		target := filepath.Clean(t.Target)
+		// ':' and ',' are separators of the runtime format "target:option,option"
+		if !filepath.IsAbs(t.Target) || target == "/" || strings.ContainsAny(target, ":,") {
+			return nil, fmt.Errorf("invalid tmpfs target: %q", t.Target)
+		}
+		if kind, ok := used[target]; ok {
+			return nil, fmt.Errorf("tmpfs target: %s conflicts with %s", target, kind)
+		}
+		if t.Size != "" && !tmpfsSizePattern.MatchString(t.Size) {
+			return nil, fmt.Errorf("invalid tmpfs size: %s", t.Size)
+		}
+		if t.Mode != "" {
+			if mode, err := strconv.ParseUint(t.Mode, 8, 32); err != nil || mode > 0o7777 {
+				return nil, fmt.Errorf("invalid tmpfs mode: %s", t.Mode)
+			}
+		}
+		used[target] = "tmpfs"
+		resolved = append(resolved, csm.TmpfsInfo{
+			Target: target,
+			Size:   t.Size,
+			Mode:   t.Mode,
+			Noexec: t.Noexec,
+		})
+	}
+
+	if readOnlyRootfs {
+		for _, t := range defaultTmpfs {
+			if _, ok := used[t.Target]; ok {
+				continue
+			}
+			resolved = append(resolved, csm.TmpfsInfo{
+				Target: t.Target,
+				Size:   t.Size,
+				Mode:   t.Mode,
+				Noexec: t.Noexec,
+			})
+		}
+	}
+	return resolved, nil
+}
+
+// tmpfsSpec formats the tmpfs mount for the runtime: "target:option,option"
+func tmpfsSpec(t csm.TmpfsInfo) string {
+	options := []string{"nosuid", "nodev"}
+	if t.Noexec {
+		options = append(options, "noexec")
+	}
+	if t.Size != "" {
+		options = append(options, "size="+t.Size)
+	}
+	if t.Mode != "" {
+		options = append(options, "mode="+t.Mode)
+	}
+	return t.Target + ":" + strings.Join(options, ",")
+}
+
+// etcFileMounts bind mounts the container etc files over the read-only rootfs
+func etcFileMounts(containerId string) []string {
+	etcDir := filepath.Join(utils.ContainerRootDir, containerId, "etc")
+	var mounts []string
+	for _, f := range etcFiles {
+		mounts = append(mounts, filepath.Join(etcDir, f)+":"+filepath.Join("/etc", f))
+	}
+	return mounts
+}
+
+func fromCsmTmpfs(tmpfs []csm.TmpfsInfo) []TmpfsModel {
+	models := []TmpfsModel{}
+	for _, t := range tmpfs {
+		models = append(models, TmpfsModel{
+			Target: t.Target,
+			Size:   t.Size,
+			Mode:   t.Mode,
+			Noexec: t.Noexec,
+		})
+	}
+	return models
+}
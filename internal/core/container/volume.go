@@ -0,0 +1,57 @@
+package container
+
+import (
+	"condenser/internal/core/volume"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// attachNamedVolumes resolves mounts whose source is a volume name ("name:/path[:options]").
+// missing volumes are created, and a volume mounted for the first time is populated with the image content.
+// returned mounts have the volume mountpoint as source
+func (s *ContainerService) attachNamedVolumes(containerId string, imageRootfs string, mounts []string) ([]string, error) {
+	var resolved []string
+	for _, m := range mounts {
+		parts := strings.SplitN(m, ":", 3)
+		if len(parts) < 2 || filepath.IsAbs(parts[0]) {
+			resolved = append(resolved, m)
+			continue
+		}
+		name, dst := parts[0], filepath.Clean(parts[1])
+		if !filepath.IsAbs(dst) {
+			return nil, fmt.Errorf("invalid mount: %s (destination must be absolute)", m)
+		}
+
+		// 1. create volume if not exist and add container reference
+		volumeInfo, err := s.volumeServiceHandler.Attach(volume.ServiceAttachModel{Name: name, ContainerId: containerId})
+		if err != nil {
+			return nil, err
+		}
+
+		// 2. populate new volume. it is marked only after the copy succeeded
+		if !volumeInfo.Populated {
+			entries, err := s.filesystemHandler.ReadDir(volumeInfo.Mountpoint)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) == 0 {
+				if err := s.populateVolume(imageRootfs, dst, volumeInfo.Mountpoint); err != nil {
+					return nil, err
+				}
+			}
+			if err := s.vsmHandler.MarkPopulated(name); err != nil {
+				return nil, err
+			}
+		}
+
+		parts[0] = volumeInfo.Mountpoint
+		resolved = append(resolved, strings.Join(parts, ":"))
+	}
+	return resolved, nil
+}
+
+// releaseVolumes removes the container reference from all volumes
+func (s *ContainerService) releaseVolumes(containerId string) error {
+	return s.vsmHandler.DetachContainer(containerId)
+}
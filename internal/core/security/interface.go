@@ -0,0 +1,17 @@
+package security
+
+import "condenser/internal/lsm"
+
+type SecurityServiceHandler interface {
+	AddSeccompProfile(addParameter ServiceSeccompModel) error
+	RemoveSeccompProfile(name string) error
+	GetSeccompProfileList() ([]SeccompProfileInfo, error)
+	GetSeccompProfile(name string) (lsm.SeccompProfile, error)
+
+	AddAppArmorProfile(addParameter ServiceAppArmorModel) error
+	ValidateAppArmorProfile(validateParameter ServiceAppArmorModel) error
+	RemoveAppArmorProfile(name string) error
+	SetAppArmorProfileMode(modeParameter ServiceAppArmorModeModel) error
+	GetAppArmorProfileList() ([]AppArmorProfileInfo, error)
+	GetAppArmorProfile(name string) (AppArmorProfileDetail, error)
+}
@@ -0,0 +1,41 @@
+package security
+
+type ServiceSeccompModel struct {
+	Name string
+	// OCI seccomp profile json
+	Profile []byte
+}
+
+type SeccompProfileInfo struct {
+	Name    string `json:"name"`
+	Builtin bool   `json:"builtin"`
+	// ids of the containers created with the profile
+	Containers []string `json:"containers"`
+}
+
+type ServiceAppArmorModel struct {
+	Name string
+	// profile in the apparmor_parser syntax. must declare "profile <name>"
+	Profile []byte
+}
+
+type ServiceAppArmorModeModel struct {
+	Name string
+	// enforce or complain
+	Mode string
+}
+
+type AppArmorProfileInfo struct {
+	Name    string `json:"name"`
+	Builtin bool   `json:"builtin"`
+	// enforce or complain
+	Mode   string `json:"mode"`
+	Loaded bool   `json:"loaded"`
+	// ids of the containers created with the profile
+	Containers []string `json:"containers"`
+}
+
+type AppArmorProfileDetail struct {
+	AppArmorProfileInfo
+	Profile string `json:"profile"`
+}
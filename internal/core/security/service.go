@@ -0,0 +1,198 @@
+package security
+
+import (
+	"condenser/internal/lsm"
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func NewSecurityService() *SecurityService {
+	return &SecurityService{
+		seccompHandler:  lsm.NewSeccompManager(),
+		appArmorHandler: lsm.NewAppArmorManager(),
+		csmHandler:      csm.NewCsmManager(csm.NewCsmStore(utils.CsmStorePath)),
+	}
+}
+
+type SecurityService struct {
+	seccompHandler  lsm.SeccompHandler
+	appArmorHandler lsm.AppArmorHandler
+	csmHandler      csm.CsmHandler
+}
+
+// == service: add seccomp profile ==
+func (s *SecurityService) AddSeccompProfile(addParameter ServiceSeccompModel) error {
+	// existing profiles are replaced. containers already created keep the spec they were created with
+	if err := s.seccompHandler.StoreProfile(addParameter.Name, addParameter.Profile); err != nil {
+		return err
+	}
+	return nil
+}
+
+// =================================
+
+// == service: remove seccomp profile ==
+func (s *SecurityService) RemoveSeccompProfile(name string) error {
+	// 1. profiles used by containers are not removed
+	used, err := s.seccompProfileUsers()
+	if err != nil {
+		return err
+	}
+	if containers := used[name]; len(containers) > 0 {
+		return fmt.Errorf("seccomp profile: %s is in use by containers: %s", name, strings.Join(containers, ", "))
+	}
+
+	// 2. remove profile
+	return s.seccompHandler.RemoveProfile(name)
+}
+
+// =================================
+
+func (s *SecurityService) GetSeccompProfileList() ([]SeccompProfileInfo, error) {
+	names, err := s.seccompHandler.GetProfileList()
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.seccompProfileUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	profileList := []SeccompProfileInfo{}
+	for _, name := range names {
+		containers := used[name]
+		if containers == nil {
+			containers = []string{}
+		}
+		profileList = append(profileList, SeccompProfileInfo{
+			Name:       name,
+			Builtin:    name == lsm.SeccompDefaultProfile,
+			Containers: containers,
+		})
+	}
+	return profileList, nil
+}
+
+func (s *SecurityService) GetSeccompProfile(name string) (lsm.SeccompProfile, error) {
+	return s.seccompHandler.GetProfile(name)
+}
+
+// seccompProfileUsers maps profile names to the containers using them
+func (s *SecurityService) seccompProfileUsers() (map[string][]string, error) {
+	return s.profileUsers(func(c csm.ContainerInfo) string { return c.SeccompProfile })
+}
+
+// appArmorProfileUsers maps profile names to the containers using them
+func (s *SecurityService) appArmorProfileUsers() (map[string][]string, error) {
+	return s.profileUsers(func(c csm.ContainerInfo) string { return c.AppArmorProfile })
+}
+
+func (s *SecurityService) profileUsers(profileOf func(csm.ContainerInfo) string) (map[string][]string, error) {
+	containerList, err := s.csmHandler.GetContainerList()
+	if err != nil {
+		return nil, err
+	}
+	used := map[string][]string{}
+	for _, c := range containerList {
+		profile := profileOf(c)
+		if profile == "" {
+			continue
+		}
+		used[profile] = append(used[profile], c.ContainerId)
+	}
+	for name := range used {
+		slices.Sort(used[name])
+	}
+	return used, nil
+}
+
+// == service: add apparmor profile ==
+func (s *SecurityService) AddAppArmorProfile(addParameter ServiceAppArmorModel) error {
+	// existing profiles are replaced and reloaded. running containers follow the reloaded rules
+	if err := s.appArmorHandler.StoreProfile(addParameter.Name, addParameter.Profile); err != nil {
+		return err
+	}
+	return nil
+}
+
+// =================================
+
+// == service: validate apparmor profile ==
+func (s *SecurityService) ValidateAppArmorProfile(validateParameter ServiceAppArmorModel) error {
+	return s.appArmorHandler.ValidateProfile(validateParameter.Name, validateParameter.Profile)
+}
+
+// =================================
+
+// == service: remove apparmor profile ==
+func (s *SecurityService) RemoveAppArmorProfile(name string) error {
+	// 1. profiles used by containers are not removed
+	used, err := s.appArmorProfileUsers()
+	if err != nil {
+		return err
+	}
+	if containers := used[name]; len(containers) > 0 {
+		return fmt.Errorf("apparmor profile: %s is in use by containers: %s", name, strings.Join(containers, ", "))
+	}
+
+	// 2. unload and remove profile
+	return s.appArmorHandler.RemoveProfile(name)
+}
+
+// =================================
+
+// == service: set apparmor profile mode ==
+func (s *SecurityService) SetAppArmorProfileMode(modeParameter ServiceAppArmorModeModel) error {
+	return s.appArmorHandler.SetProfileMode(modeParameter.Name, modeParameter.Mode)
+}
+
+// =================================
+
+func (s *SecurityService) GetAppArmorProfileList() ([]AppArmorProfileInfo, error) {
+	statusList, err := s.appArmorHandler.GetProfileList()
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.appArmorProfileUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	profileList := []AppArmorProfileInfo{}
+	for _, status := range statusList {
+		profileList = append(profileList, toAppArmorProfileInfo(status, used))
+	}
+	return profileList, nil
+}
+
+func (s *SecurityService) GetAppArmorProfile(name string) (AppArmorProfileDetail, error) {
+	status, data, err := s.appArmorHandler.GetProfile(name)
+	if err != nil {
+		return AppArmorProfileDetail{}, err
+	}
+	used, err := s.appArmorProfileUsers()
+	if err != nil {
+		return AppArmorProfileDetail{}, err
+	}
+	return AppArmorProfileDetail{
+		AppArmorProfileInfo: toAppArmorProfileInfo(status, used),
+		Profile:             string(data),
+	}, nil
+}
+
+func toAppArmorProfileInfo(status lsm.AppArmorProfileStatus, used map[string][]string) AppArmorProfileInfo {
+	containers := used[status.Name]
+	if containers == nil {
+		containers = []string{}
+	}
+	return AppArmorProfileInfo{
+		Name:       status.Name,
+		Builtin:    status.Name == lsm.AppArmorProfile,
+		Mode:       status.Mode,
+		Loaded:     status.Loaded,
+		Containers: containers,
+	}
+}
@@ -0,0 +1,5 @@
+package system
+
+type SystemServiceHandler interface {
+	Prune(pruneParameter ServicePruneModel) (PruneResult, error)
+}
@@ -0,0 +1,20 @@
+package system
+
+type ServicePruneModel struct {
+	// go duration string. only resources older than this are pruned
+	Age string
+	// container states to prune: created, stopped (default: stopped)
+	States []string
+	// "key" or "key=value". applied to containers
+	Labels []string
+}
+
+type PruneResult struct {
+	Containers        []string `json:"containers"`
+	Images            []string `json:"images"`
+	OrphanDirectories []string `json:"orphanDirectories"`
+	OrphanCgroups     []string `json:"orphanCgroups"`
+	OrphanAllocations []string `json:"orphanAllocations"`
+	SpaceReclaimed    int64    `json:"spaceReclaimed"`
+	Errors            []string `json:"errors,omitempty"`
+}
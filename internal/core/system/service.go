@@ -0,0 +1,290 @@
+package system
+
+import (
+	"condenser/internal/core/container"
+	"condenser/internal/core/image"
+	"condenser/internal/core/network"
+	"condenser/internal/store/csm"
+	"condenser/internal/store/ilm"
+	"condenser/internal/store/ipam"
+	"condenser/internal/utils"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// orphan resources younger than this are kept.
+// container create allocates address and directories around the CSM entry creation
+const orphanGracePeriod = 1 * time.Minute
+
+func NewSystemService() *SystemService {
+	return &SystemService{
+		filesystemHandler: utils.NewFilesystemExecutor(),
+
+		ipamHandler: ipam.NewIpamManager(ipam.NewIpamStore(utils.IpamStorePath)),
+		ilmHandler:  ilm.NewIlmManager(ilm.NewIlmStore(utils.IlmStorePath)),
+		csmHandler:  csm.NewCsmManager(csm.NewCsmStore(utils.CsmStorePath)),
+
+		containerServiceHandler: container.NewContaierService(),
+		imageServiceHandler:     image.NewImageService(),
+		networkServiceHandler:   network.NewNetworkService(),
+	}
+}
+
+type SystemService struct {
+	filesystemHandler utils.FilesystemHandler
+
+	ipamHandler ipam.IpamHandler
+	ilmHandler  ilm.IlmHandler
+	csmHandler  csm.CsmHandler
+
+	containerServiceHandler container.ContainerServiceHandler
+	imageServiceHandler     image.ImageServiceHandler
+	networkServiceHandler   network.NetworkServiceHandler
+}
+
+// == service: prune ==
+func (s *SystemService) Prune(pruneParameter ServicePruneModel) (PruneResult, error) {
+	// 1. validate filters
+	var age time.Duration
+	if pruneParameter.Age != "" {
+		d, err := time.ParseDuration(pruneParameter.Age)
+		if err != nil || d < 0 {
+			return PruneResult{}, fmt.Errorf("invalid age: %s", pruneParameter.Age)
+		}
+		age = d
+	}
+	states := pruneParameter.States
+	if len(states) == 0 {
+		states = []string{"stopped"}
+	}
+	for _, st := range states {
+		if st != "created" && st != "stopped" {
+			return PruneResult{}, fmt.Errorf("invalid state: %s (created, stopped)", st)
+		}
+	}
+	cutoff := time.Now().Add(-age)
+
+	result := PruneResult{
+		Containers:        []string{},
+		Images:            []string{},
+		OrphanDirectories: []string{},
+		OrphanCgroups:     []string{},
+		OrphanAllocations: []string{},
+	}
+
+	// 2. containers
+	if err := s.pruneContainers(&result, states, pruneParameter.Labels, cutoff); err != nil {
+		return result, err
+	}
+
+	// 3. images not referenced by any container
+	if err := s.pruneImages(&result, cutoff); err != nil {
+		return result, err
+	}
+
+	// 4. orphan resources of containers which do not exist in CSM
+	containerList, err := s.csmHandler.GetContainerList()
+	if err != nil {
+		return result, err
+	}
+	known := map[string]bool{}
+	for _, c := range containerList {
+		known[c.ContainerId] = true
+	}
+	orphanCutoff := cutoff
+	if graceCutoff := time.Now().Add(-orphanGracePeriod); graceCutoff.Before(orphanCutoff) {
+		orphanCutoff = graceCutoff
+	}
+	s.pruneOrphanDirectories(&result, known, orphanCutoff)
+	s.pruneOrphanCgroups(&result, known, orphanCutoff)
+	if err := s.pruneOrphanAllocations(&result, known, orphanCutoff); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (s *SystemService) pruneContainers(result *PruneResult, states []string, labels []string, cutoff time.Time) error {
+	containerList, err := s.csmHandler.GetContainerList()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containerList {
+		if !slices.Contains(states, c.State) {
+			continue
+		}
+		// age: time of the last state change
+		changedAt := c.CreatedAt
+		if c.State == "stopped" {
+			changedAt = c.StoppedAt
+		}
+		if changedAt.After(cutoff) {
+			continue
+		}
+		if !container.MatchLabels(c.Labels, labels) {
+			continue
+		}
+
+		size := s.containerDirSize(c.ContainerId)
+		if _, err := s.containerServiceHandler.Delete(container.ServiceDeleteModel{ContainerId: c.ContainerId}); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("container: %s: %v", c.ContainerId, err))
+			continue
+		}
+		result.Containers = append(result.Containers, c.ContainerId)
+		result.SpaceReclaimed += size
+	}
+	return nil
+}
+
+func (s *SystemService) pruneImages(result *PruneResult, cutoff time.Time) error {
+	containerList, err := s.csmHandler.GetContainerList()
+	if err != nil {
+		return err
+	}
+	// keyed by ILM repository and reference
+	used := map[[2]string]bool{}
+	for _, c := range containerList {
+		used[[2]string{c.Repository, c.Reference}] = true
+	}
+
+	imageList, err := s.ilmHandler.GetImageList()
+	if err != nil {
+		return err
+	}
+	for _, img := range imageList {
+		if used[[2]string{img.Repository, img.Reference}] || img.CreatedAt.After(cutoff) {
+			continue
+		}
+
+		imageRef := imageName(img.Repository, img.Reference)
+		var size int64
+		if bundlePath, err := s.ilmHandler.GetBundlePath(img.Repository, img.Reference); err == nil {
+			size = s.filesystemHandler.DirSize(bundlePath)
+		}
+		if err := s.imageServiceHandler.Remove(image.ServiceRemoveModel{Image: imageRef}); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("image: %s: %v", imageRef, err))
+			continue
+		}
+		result.Images = append(result.Images, imageRef)
+		result.SpaceReclaimed += size
+	}
+	return nil
+}
+
+func (s *SystemService) pruneOrphanDirectories(result *PruneResult, known map[string]bool, cutoff time.Time) {
+	entries, err := s.filesystemHandler.ReadDir(utils.ContainerRootDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || known[e.Name()] || !s.olderThan(e, cutoff) {
+			continue
+		}
+		dir := filepath.Join(utils.ContainerRootDir, e.Name())
+		// rootfs still mounted: removing it would go through the overlay
+		if s.filesystemHandler.IsMountPoint(filepath.Join(dir, "merged")) {
+			result.Errors = append(result.Errors, fmt.Sprintf("directory: %s: rootfs is still mounted", dir))
+			continue
+		}
+		size := s.filesystemHandler.DirSize(dir)
+		if err := s.filesystemHandler.RemoveAll(dir); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("directory: %s: %v", dir, err))
+			continue
+		}
+		result.OrphanDirectories = append(result.OrphanDirectories, dir)
+		result.SpaceReclaimed += size
+	}
+}
+
+func (s *SystemService) pruneOrphanCgroups(result *PruneResult, known map[string]bool, cutoff time.Time) {
+	entries, err := s.filesystemHandler.ReadDir(utils.CgroupRuntimeDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || known[e.Name()] || !s.olderThan(e, cutoff) {
+			continue
+		}
+		// cgroup directory can be removed only when no process is left
+		dir := filepath.Join(utils.CgroupRuntimeDir, e.Name())
+		if err := s.filesystemHandler.Remove(dir); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("cgroup: %s: %v", dir, err))
+			continue
+		}
+		result.OrphanCgroups = append(result.OrphanCgroups, dir)
+	}
+}
+
+func (s *SystemService) pruneOrphanAllocations(result *PruneResult, known map[string]bool, cutoff time.Time) error {
+	poolList, err := s.ipamHandler.GetPoolList()
+	if err != nil {
+		return err
+	}
+	for _, p := range poolList {
+		for addr, a := range p.Allocations {
+			if known[a.ContainerId] || a.AssignedAt.After(cutoff) {
+				continue
+			}
+			// remove forward rules left with the allocation
+			for _, f := range a.Forwards {
+				if err := s.networkServiceHandler.RemoveForwardingRule(
+					a.ContainerId,
+					network.ServiceNetworkModel{
+						HostPort:      strconv.Itoa(f.HostPort),
+						ContainerPort: strconv.Itoa(f.ContainerPort),
+						Protocol:      f.Protocol,
+					},
+				); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("forward rule: %s: %v", a.ContainerId, err))
+				}
+			}
+			if err := s.ipamHandler.Release(a.ContainerId); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("allocation: %s: %v", addr, err))
+				continue
+			}
+			result.OrphanAllocations = append(result.OrphanAllocations, addr+" ("+a.ContainerId+")")
+		}
+	}
+	return nil
+}
+
+func (s *SystemService) olderThan(e fs.DirEntry, cutoff time.Time) bool {
+	info, err := e.Info()
+	if err != nil {
+		return false
+	}
+	return info.ModTime().Before(cutoff)
+}
+
+// containerDirSize sums the container directory except the rootfs mount point.
+// merged is the overlay of the image layers and the upper dir, which are counted elsewhere
+func (s *SystemService) containerDirSize(containerId string) int64 {
+	dir := filepath.Join(utils.ContainerRootDir, containerId)
+	entries, err := s.filesystemHandler.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var size int64
+	for _, e := range entries {
+		if e.Name() == "merged" {
+			continue
+		}
+		size += s.filesystemHandler.DirSize(filepath.Join(dir, e.Name()))
+	}
+	return size
+}
+
+// imageName formats the ILM key as an image string accepted by the image service.
+// digest references are joined with "@"
+func imageName(repository, reference string) string {
+	if strings.Contains(reference, ":") {
+		return repository + "@" + reference
+	}
+	return repository + ":" + reference
+}
@@ -0,0 +1,10 @@
+package volume
+
+type VolumeServiceHandler interface {
+	Create(createParameter ServiceCreateModel) (VolumeInfo, error)
+	Remove(removeParameter ServiceRemoveModel) error
+	Attach(attachParameter ServiceAttachModel) (VolumeInfo, error)
+	Prune() (PruneResult, error)
+	GetVolumeList() ([]VolumeInfo, error)
+	GetVolumeByName(name string) (VolumeInfo, error)
+}
@@ -0,0 +1,35 @@
+package volume
+
+import "time"
+
+type ServiceCreateModel struct {
+	Name   string
+	Labels map[string]string
+}
+
+type ServiceRemoveModel struct {
+	Name string
+}
+
+type ServiceAttachModel struct {
+	Name        string
+	ContainerId string
+}
+
+type VolumeInfo struct {
+	Name       string            `json:"name"`
+	Mountpoint string            `json:"mountpoint"`
+	Labels     map[string]string `json:"labels"`
+	// ids of the containers mounting the volume
+	Containers []string `json:"containers"`
+	RefCount   int      `json:"refCount"`
+	// true after the image content was copied on the first mount
+	Populated bool      `json:"populated"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PruneResult struct {
+	Volumes        []string `json:"volumes"`
+	SpaceReclaimed int64    `json:"spaceReclaimed"`
+	Errors         []string `json:"errors,omitempty"`
+}
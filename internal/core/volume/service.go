@@ -0,0 +1,207 @@
+package volume
+
+import (
+	"condenser/internal/store/csm"
+	"condenser/internal/store/vsm"
+	"condenser/internal/utils"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func NewVolumeService() *VolumeService {
+	return &VolumeService{
+		filesystemHandler: utils.NewFilesystemExecutor(),
+
+		vsmHandler: vsm.NewVsmManager(vsm.NewVsmStore(utils.VsmStorePath)),
+		csmHandler: csm.NewCsmManager(csm.NewCsmStore(utils.CsmStorePath)),
+	}
+}
+
+type VolumeService struct {
+	filesystemHandler utils.FilesystemHandler
+
+	vsmHandler vsm.VsmHandler
+	csmHandler csm.CsmHandler
+}
+
+// == service: create ==
+func (s *VolumeService) Create(createParameter ServiceCreateModel) (VolumeInfo, error) {
+	// 1. validate name
+	name := createParameter.Name
+	if !volumeNamePattern.MatchString(name) {
+		return VolumeInfo{}, fmt.Errorf("invalid volume name: %q", name)
+	}
+	if s.vsmHandler.IsVolumeExist(name) {
+		return VolumeInfo{}, fmt.Errorf("volume: %s already exists", name)
+	}
+
+	// 2. create volume directory
+	mountpoint := filepath.Join(utils.VolumeRootDir, name)
+	if err := s.filesystemHandler.MkdirAll(mountpoint, 0o755); err != nil {
+		return VolumeInfo{}, fmt.Errorf("create volume directory failed: %w", err)
+	}
+
+	// 3. create VSM entry
+	if err := s.vsmHandler.StoreVolume(name, mountpoint, createParameter.Labels); err != nil {
+		return VolumeInfo{}, err
+	}
+
+	return s.GetVolumeByName(name)
+}
+
+// =================================
+
+// == service: remove ==
+func (s *VolumeService) Remove(removeParameter ServiceRemoveModel) error {
+	// 1. drop references of containers which no longer exist
+	if err := s.releaseStaleReferences(); err != nil {
+		return err
+	}
+
+	// 2. remove volume
+	return s.removeVolume(removeParameter.Name)
+}
+
+func (s *VolumeService) removeVolume(name string) error {
+	// remove VSM entry with the volume directory. volumes in use are refused
+	return s.vsmHandler.RemoveVolume(name, func(volumeInfo vsm.VolumeInfo) error {
+		if err := s.filesystemHandler.RemoveAll(volumeInfo.Mountpoint); err != nil {
+			return fmt.Errorf("remove volume directory failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// =================================
+
+// == service: attach ==
+func (s *VolumeService) Attach(attachParameter ServiceAttachModel) (VolumeInfo, error) {
+	// 1. validate name
+	name := attachParameter.Name
+	if !volumeNamePattern.MatchString(name) {
+		return VolumeInfo{}, fmt.Errorf("invalid volume name: %q", name)
+	}
+
+	// 2. create VSM entry if not exist and add container reference at once.
+	//    prune does not remove the volume created here before it is referenced
+	v, err := s.vsmHandler.AttachContainer(name, filepath.Join(utils.VolumeRootDir, name), attachParameter.ContainerId)
+	if err != nil {
+		return VolumeInfo{}, err
+	}
+
+	// 3. create volume directory. the referenced volume is not removed meanwhile
+	if err := s.filesystemHandler.MkdirAll(v.Mountpoint, 0o755); err != nil {
+		return VolumeInfo{}, fmt.Errorf("create volume directory failed: %w", err)
+	}
+	return toVolumeInfo(v), nil
+}
+
+// =================================
+
+// == service: prune ==
+func (s *VolumeService) Prune() (PruneResult, error) {
+	result := PruneResult{Volumes: []string{}}
+
+	// 1. drop references of containers which no longer exist
+	if err := s.releaseStaleReferences(); err != nil {
+		return result, err
+	}
+
+	// 2. remove volumes not referenced by any container
+	volumeList, err := s.vsmHandler.GetVolumeList()
+	if err != nil {
+		return result, err
+	}
+	for _, v := range volumeList {
+		if len(v.Containers) > 0 {
+			continue
+		}
+		size := s.filesystemHandler.DirSize(v.Mountpoint)
+		if err := s.removeVolume(v.Name); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("volume: %s: %v", v.Name, err))
+			continue
+		}
+		result.Volumes = append(result.Volumes, v.Name)
+		result.SpaceReclaimed += size
+	}
+	slices.Sort(result.Volumes)
+	return result, nil
+}
+
+// =================================
+
+func (s *VolumeService) GetVolumeList() ([]VolumeInfo, error) {
+	volumeList, err := s.vsmHandler.GetVolumeList()
+	if err != nil {
+		return nil, err
+	}
+
+	var volumeInfo []VolumeInfo
+	for _, v := range volumeList {
+		volumeInfo = append(volumeInfo, toVolumeInfo(v))
+	}
+	slices.SortFunc(volumeInfo, func(a, b VolumeInfo) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return volumeInfo, nil
+}
+
+func (s *VolumeService) GetVolumeByName(name string) (VolumeInfo, error) {
+	v, err := s.vsmHandler.GetVolumeByName(name)
+	if err != nil {
+		return VolumeInfo{}, err
+	}
+	return toVolumeInfo(v), nil
+}
+
+// releaseStaleReferences detaches containers removed without releasing their volumes
+func (s *VolumeService) releaseStaleReferences() error {
+	containerList, err := s.csmHandler.GetContainerList()
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, c := range containerList {
+		known[c.ContainerId] = true
+	}
+
+	volumeList, err := s.vsmHandler.GetVolumeList()
+	if err != nil {
+		return err
+	}
+	for _, v := range volumeList {
+		for _, containerId := range v.Containers {
+			if known[containerId] {
+				continue
+			}
+			if err := s.vsmHandler.DetachContainer(containerId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func toVolumeInfo(v vsm.VolumeInfo) VolumeInfo {
+	labels := v.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	containers := v.Containers
+	if containers == nil {
+		containers = []string{}
+	}
+	return VolumeInfo{
+		Name:       v.Name,
+		Mountpoint: v.Mountpoint,
+		Labels:     labels,
+		Containers: containers,
+		RefCount:   len(containers),
+		Populated:  v.Populated,
+		CreatedAt:  v.CreatedAt,
+	}
+}
@@ -55,20 +55,24 @@ type Resolver struct {
 	ResolveMap  map[string]ContainerMeta
 	ipamHandler ipam.IpamHandler
 	csmHandler  csm.CsmHandler
+
+	mu sync.RWMutex
 }
 
+// Refresh rebuilds the cache from IPAM and CSM. called on every CSM change,
+// so renamed containers are logged with the new name
 func (r *Resolver) Refresh() {
-	r.ResolveMap = map[string]ContainerMeta{}
+	resolveMap := map[string]ContainerMeta{}
 	pool, _ := r.ipamHandler.GetPoolList()
 	for _, p := range pool {
 		for addr, info := range p.Allocations {
-			if _, ok := r.ResolveMap[addr]; !ok {
+			if _, ok := resolveMap[addr]; !ok {
 				containerName, err := r.csmHandler.GetContainerNameById(info.ContainerId)
 				if err != nil {
 					continue
 				}
 				spiffeId, _ := r.csmHandler.GetSpiffeById(info.ContainerId)
-				r.ResolveMap[addr] = ContainerMeta{
+				resolveMap[addr] = ContainerMeta{
 					ContainerId:   info.ContainerId,
 					ContainerName: containerName,
 					Ipv4:          addr,
@@ -78,6 +82,17 @@ func (r *Resolver) Refresh() {
 			}
 		}
 	}
+
+	r.mu.Lock()
+	r.ResolveMap = resolveMap
+	r.mu.Unlock()
+}
+
+func (r *Resolver) Lookup(addr string) (ContainerMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.ResolveMap[addr]
+	return meta, ok
 }
 
 func (r *Resolver) Watch(ctx context.Context) error {
@@ -435,7 +450,7 @@ func (e *Enricher) enrich(rr RawRecord, rawLine []byte) Enriched {
 	if srcIp != "" {
 		if srcIsContainer {
 			src.Kind = "container"
-			containerMeta, ok := e.Resolver.ResolveMap[srcIp]
+			containerMeta, ok := e.Resolver.Lookup(srcIp)
 			if !ok {
 				src.Kind = "container_unresolved"
 			}
@@ -447,7 +462,7 @@ func (e *Enricher) enrich(rr RawRecord, rawLine []byte) Enriched {
 	if dstIp != "" {
 		if dstIsContainer {
 			dst.Kind = "container"
-			containerMeta, ok := e.Resolver.ResolveMap[dstIp]
+			containerMeta, ok := e.Resolver.Lookup(dstIp)
 			if !ok {
 				dst.Kind = "container_unresolved"
 			}
@@ -10,6 +10,7 @@ import (
 	"condenser/internal/store/ilm"
 	"condenser/internal/store/ipam"
 	"condenser/internal/store/npm"
+	"condenser/internal/store/vsm"
 	"condenser/internal/utils"
 	"fmt"
 	"net"
@@ -30,7 +31,9 @@ func NewBootstrapManager() *BootstrapManager {
 		csmStoreHandler:   csm.NewCsmStore(utils.CsmStorePath),
 		ilmStoreHandler:   ilm.NewIlmStore(utils.IlmStorePath),
 		npmStoreHandler:   npm.NewNpmStore(utils.NpmStorePath),
+		vsmStoreHandler:   vsm.NewVsmStore(utils.VsmStorePath),
 		appArmorHandler:   lsm.NewAppArmorManager(),
+		seccompHandler:    lsm.NewSeccompManager(),
 	}
 }
 
@@ -45,7 +48,9 @@ type BootstrapManager struct {
 	csmStoreHandler   csm.CsmStoreHandler
 	ilmStoreHandler   ilm.IlmStoreHandler
 	npmStoreHandler   npm.NpmStoreHandler
+	vsmStoreHandler   vsm.VsmStoreHandler
 	appArmorHandler   lsm.AppArmorHandler
+	seccompHandler    lsm.SeccompHandler
 }
 
 func (m *BootstrapManager) SetupRuntime() error {
@@ -79,6 +84,11 @@ func (m *BootstrapManager) SetupRuntime() error {
 		return err
 	}
 
+	// 6-1. setup VSM (Volume State Manager)
+	if err := m.setupVsm(); err != nil {
+		return err
+	}
+
 	// 7. setup certificate
 	if err := m.setupCertificate(); err != nil {
 		return err
@@ -99,6 +109,11 @@ func (m *BootstrapManager) SetupRuntime() error {
 		return err
 	}
 
+	// 9. setup seccomp
+	if err := m.setupSeccomp(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -107,6 +122,7 @@ func (m *BootstrapManager) setupRuntimeDirectory() error {
 		utils.ContainerRootDir,
 		utils.ImageRootDir,
 		utils.LayerRootDir,
+		utils.VolumeRootDir,
 		utils.StoreDir,
 		utils.AuditLogDir,
 		utils.CertDir,
@@ -162,6 +178,18 @@ func (m *BootstrapManager) enableCgroupControllers() error {
 		}
 	}
 
+	// optional controllers
+	//   io is not delegated on every host. if enabling failed, io.max is rejected on create
+	optionalControllers := []string{
+		"io",
+	}
+	for _, c := range optionalControllers {
+		if enabled[c] {
+			continue
+		}
+		_ = m.writeCgroupController("+" + c)
+	}
+
 	return nil
 }
 
@@ -219,14 +247,24 @@ func (m *BootstrapManager) setupNpm() error {
 	return m.npmStoreHandler.SetNetworkPolicy()
 }
 
+func (m *BootstrapManager) setupVsm() error {
+	return m.vsmStoreHandler.SetVolumeState()
+}
+
 func (m *BootstrapManager) setupAppArmor() error {
 	if err := m.appArmorHandler.EnsureRaindDefaultProfile(); err != nil {
 		// if apparmor setting failed, runtime ignore apparmor setting
 		return nil
 	}
+	// custom profiles failed to load are reported on container create
+	_ = m.appArmorHandler.LoadProfiles()
 	return nil
 }
 
+func (m *BootstrapManager) setupSeccomp() error {
+	return m.seccompHandler.EnsureRaindDefaultSeccompProfile()
+}
+
 func (m *BootstrapManager) setupNetwork() error {
 	// 1. create bridge interface
 	if err := m.createBridgeInterface(); err != nil {
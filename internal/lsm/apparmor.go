@@ -9,6 +9,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -108,10 +110,54 @@ profile raind-default flags=(attach_disconnected,mediate_deleted) {
 const (
 	AppArmorDir     = "/etc/raind/lsm/apparmor"
 	AppArmorProfile = "raind-default"
+	// profiles managed by condenser are kept in this namespace, so host profiles are never replaced or unloaded
+	AppArmorProfilePrefix = "raind-"
+	// runs the container without apparmor confinement
+	AppArmorUnconfined = "unconfined"
+
+	AppArmorModeEnforce  = "enforce"
+	AppArmorModeComplain = "complain"
+
+	appArmorProfilesPath = "/sys/kernel/security/apparmor/profiles"
 )
 
+var appArmorProfileNamePattern = regexp.MustCompile(`^raind-[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// matches the header of the profile and its flags. e.g. profile web flags=(complain) {
+var appArmorFlagsPattern = regexp.MustCompile(`\s+flags\s*=\s*\(([^)]*)\)`)
+
+// matches include directives. e.g. #include <abstractions/base>, include if exists "/path"
+var appArmorIncludePattern = regexp.MustCompile(`^#?include(?:\s+if\s+exists)?\s+(\S.*)$`)
+
+// matches the start of profile and hat declarations: profile <name>, hat <name>, ^<hat>, /attach/path, @{var}, :ns:name
+// the block of a declaration opens at the end of the line. rules end with ","
+var appArmorDeclarationPattern = regexp.MustCompile(`^(?:profile\s+("[^"]*"|[^\s{]+)|hat\s+\S+|\^\S+|/\S*|@\{\S*|:\S+).*\{$`)
+
+// include directories allowed in custom profiles. both are shipped with apparmor under /etc/apparmor.d
+var appArmorIncludeDirs = []string{"abstractions", "tunables"}
+
+// suffixes of the files written while a profile is validated or loaded.
+// they are left behind when the daemon stops in between
+var appArmorTempSuffixes = []string{".validate", ".load", ".tmp"}
+
 type AppArmorHandler interface {
 	EnsureRaindDefaultProfile() error
+	LoadProfiles() error
+	IsEnabled() bool
+	IsProfileLoaded(name string) (bool, error)
+	ValidateProfile(name string, data []byte) error
+	StoreProfile(name string, data []byte) error
+	RemoveProfile(name string) error
+	SetProfileMode(name string, mode string) error
+	GetProfile(name string) (AppArmorProfileStatus, []byte, error)
+	GetProfileList() ([]AppArmorProfileStatus, error)
+}
+
+// mode is the kernel mode when loaded, otherwise the mode written in the profile
+type AppArmorProfileStatus struct {
+	Name   string
+	Mode   string
+	Loaded bool
 }
 
 func NewAppArmorManager() *AppArmorManager {
@@ -163,6 +209,270 @@ func (m *AppArmorManager) EnsureRaindDefaultProfile() error {
 	return nil
 }
 
+// LoadProfiles loads the custom profiles into the kernel.
+// loaded profiles are lost on reboot, so bootstrap reloads the stored files
+func (m *AppArmorManager) LoadProfiles() error {
+	if !m.isAAEnabled() {
+		return fmt.Errorf("apparmor is not enabled on this host")
+	}
+	// 1. remove temporary files left by an interrupted validate or store
+	if err := m.removeTempFiles(); err != nil {
+		return err
+	}
+
+	// 2. load stored profiles
+	names, err := m.profileNames()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		if name == AppArmorProfile {
+			continue
+		}
+		if err := m.loadProfileWithParser(m.profilePath(name)); err != nil {
+			errs = append(errs, fmt.Errorf("load %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *AppArmorManager) IsEnabled() bool {
+	return m.isAAEnabled()
+}
+
+func (m *AppArmorManager) IsProfileLoaded(name string) (bool, error) {
+	return m.isProfilleLoaded(name)
+}
+
+// ValidateProfile checks the profile with apparmor_parser without loading it into the kernel
+func (m *AppArmorManager) ValidateProfile(name string, data []byte) error {
+	if err := validateAppArmorProfileName(name); err != nil {
+		return err
+	}
+	if err := checkProfileHeader(name, data); err != nil {
+		return err
+	}
+	if err := checkProfileContent(name, data); err != nil {
+		return err
+	}
+	tmp := filepath.Join(AppArmorDir, "."+name+".validate")
+	if err := m.writeFileAtomic(tmp, data, 0644); err != nil {
+		return err
+	}
+	defer m.filesystemHandler.Remove(tmp)
+
+	return m.runParser("-Q", "-K", tmp)
+}
+
+// StoreProfile validates, writes and loads a custom profile.
+// an existing profile of the same name is replaced. the built-in profile is not overwritten
+func (m *AppArmorManager) StoreProfile(name string, data []byte) error {
+	// 1. validate
+	if name == AppArmorProfile {
+		return fmt.Errorf("apparmor profile: %s is built-in", name)
+	}
+	if !m.isAAEnabled() {
+		return fmt.Errorf("apparmor is not enabled on this host")
+	}
+	if err := m.ValidateProfile(name, data); err != nil {
+		return err
+	}
+	// a profile loaded by others in the namespace is not replaced
+	if _, err := m.filesystemHandler.Stat(m.profilePath(name)); err != nil {
+		if !m.filesystemHandler.IsNotExist(err) {
+			return err
+		}
+		loaded, err := m.isProfilleLoaded(name)
+		if err != nil {
+			return fmt.Errorf("check profile loaded: %w", err)
+		}
+		if loaded {
+			return fmt.Errorf("apparmor profile: %s is loaded but not managed by condenser", name)
+		}
+	}
+
+	// 2. load before replacing the file, so a rejected profile does not survive the next bootstrap
+	tmp := filepath.Join(AppArmorDir, "."+name+".load")
+	if err := m.writeFileAtomic(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := m.loadProfileWithParser(tmp); err != nil {
+		_ = m.filesystemHandler.Remove(tmp)
+		return err
+	}
+
+	// 3. write
+	if err := m.filesystemHandler.Rename(tmp, m.profilePath(name)); err != nil {
+		_ = m.filesystemHandler.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// RemoveProfile unloads the profile from the kernel and removes the file
+func (m *AppArmorManager) RemoveProfile(name string) error {
+	if err := validateAppArmorProfileName(name); err != nil {
+		return err
+	}
+	if name == AppArmorProfile {
+		return fmt.Errorf("apparmor profile: %s is built-in", name)
+	}
+	path := m.profilePath(name)
+	if _, err := m.filesystemHandler.Stat(path); err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return fmt.Errorf("apparmor profile: %s not found", name)
+		}
+		return err
+	}
+
+	loaded, err := m.isProfilleLoaded(name)
+	if err != nil {
+		return fmt.Errorf("check profile loaded: %w", err)
+	}
+	if loaded {
+		if err := m.runParser("-R", path); err != nil {
+			return err
+		}
+	}
+	return m.filesystemHandler.Remove(path)
+}
+
+// SetProfileMode rewrites the flags of the profile and reloads it.
+// the mode is kept in the file, so it survives the reload on bootstrap
+func (m *AppArmorManager) SetProfileMode(name string, mode string) error {
+	// 1. validate
+	if err := validateAppArmorProfileName(name); err != nil {
+		return err
+	}
+	if name == AppArmorProfile {
+		return fmt.Errorf("apparmor profile: %s is built-in", name)
+	}
+	if mode != AppArmorModeEnforce && mode != AppArmorModeComplain {
+		return fmt.Errorf("invalid apparmor mode: %q (expected %s or %s)", mode, AppArmorModeEnforce, AppArmorModeComplain)
+	}
+	if !m.isAAEnabled() {
+		return fmt.Errorf("apparmor is not enabled on this host")
+	}
+
+	// 2. rewrite flags
+	data, err := m.filesystemHandler.ReadFile(m.profilePath(name))
+	if err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return fmt.Errorf("apparmor profile: %s not found", name)
+		}
+		return err
+	}
+	updated, err := setProfileMode(name, data, mode)
+	if err != nil {
+		return err
+	}
+
+	// 3. validate and load
+	return m.StoreProfile(name, updated)
+}
+
+func (m *AppArmorManager) GetProfile(name string) (AppArmorProfileStatus, []byte, error) {
+	if err := validateAppArmorProfileName(name); err != nil {
+		return AppArmorProfileStatus{}, nil, err
+	}
+	data, err := m.filesystemHandler.ReadFile(m.profilePath(name))
+	if err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return AppArmorProfileStatus{}, nil, fmt.Errorf("apparmor profile: %s not found", name)
+		}
+		return AppArmorProfileStatus{}, nil, err
+	}
+	kernelModes := m.loadedProfileModes()
+	return m.profileStatus(name, data, kernelModes), data, nil
+}
+
+func (m *AppArmorManager) GetProfileList() ([]AppArmorProfileStatus, error) {
+	names, err := m.profileNames()
+	if err != nil {
+		return nil, err
+	}
+	kernelModes := m.loadedProfileModes()
+
+	statusList := []AppArmorProfileStatus{}
+	for _, name := range names {
+		data, err := m.filesystemHandler.ReadFile(m.profilePath(name))
+		if err != nil {
+			return nil, err
+		}
+		statusList = append(statusList, m.profileStatus(name, data, kernelModes))
+	}
+	return statusList, nil
+}
+
+func (m *AppArmorManager) profileStatus(name string, data []byte, kernelModes map[string]string) AppArmorProfileStatus {
+	if mode, ok := kernelModes[name]; ok {
+		return AppArmorProfileStatus{Name: name, Mode: mode, Loaded: true}
+	}
+	return AppArmorProfileStatus{Name: name, Mode: profileMode(name, data)}
+}
+
+func (m *AppArmorManager) profileNames() ([]string, error) {
+	entries, err := m.filesystemHandler.ReadDir(AppArmorDir)
+	if err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || isAppArmorTempFile(name) || !appArmorProfileNamePattern.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (m *AppArmorManager) removeTempFiles() error {
+	entries, err := m.filesystemHandler.ReadDir(AppArmorDir)
+	if err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isAppArmorTempFile(e.Name()) {
+			continue
+		}
+		if err := m.filesystemHandler.Remove(filepath.Join(AppArmorDir, e.Name())); err != nil && !m.filesystemHandler.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *AppArmorManager) profilePath(name string) string {
+	return filepath.Join(AppArmorDir, name)
+}
+
+// loadedProfileModes maps the loaded profile names to their mode.
+// lines of the kernel list are "name (mode)"
+func (m *AppArmorManager) loadedProfileModes() map[string]string {
+	modes := map[string]string{}
+	b, err := m.filesystemHandler.ReadFile(appArmorProfilesPath)
+	if err != nil {
+		return modes
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		i := strings.LastIndex(line, " (")
+		if i < 0 || !strings.HasSuffix(line, ")") {
+			continue
+		}
+		modes[line[:i]] = line[i+2 : len(line)-1]
+	}
+	return modes
+}
+
 func (m *AppArmorManager) isAAEnabled() bool {
 	b, err := m.filesystemHandler.ReadFile("/sys/module/apparmor/parameters/enabled")
 	if err == nil {
@@ -175,7 +485,7 @@ func (m *AppArmorManager) isAAEnabled() bool {
 }
 
 func (m *AppArmorManager) isProfilleLoaded(profileName string) (bool, error) {
-	b, err := m.filesystemHandler.ReadFile("/sys/kernel/security/apparmor/profiles")
+	b, err := m.filesystemHandler.ReadFile(appArmorProfilesPath)
 	if err != nil {
 		return false, nil
 	}
@@ -188,10 +498,14 @@ func (m *AppArmorManager) isProfilleLoaded(profileName string) (bool, error) {
 }
 
 func (m *AppArmorManager) loadProfileWithParser(profilePath string) error {
+	return m.runParser("-r", profilePath)
+}
+
+func (m *AppArmorManager) runParser(args ...string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "apparmor_parser", "-r", profilePath)
+	cmd := exec.CommandContext(ctx, "apparmor_parser", args...)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &out
@@ -221,3 +535,137 @@ func (m *AppArmorManager) writeFileAtomic(path string, data []byte, perm os.File
 	}
 	return nil
 }
+
+// isAppArmorTempFile reports the file is written by writeFileAtomic (<name>.tmp),
+// ValidateProfile (.<name>.validate) or StoreProfile (.<name>.load)
+func isAppArmorTempFile(name string) bool {
+	if strings.HasSuffix(name, ".tmp") {
+		return true
+	}
+	if !strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, suffix := range appArmorTempSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAppArmorProfileName(name string) error {
+	if !appArmorProfileNamePattern.MatchString(name) || strings.HasSuffix(name, ".tmp") {
+		return fmt.Errorf("invalid apparmor profile name: %q (must start with %s)", name, AppArmorProfilePrefix)
+	}
+	return nil
+}
+
+// profileHeader finds "profile <name> [flags=(...)] {" and returns the index of the header
+func profileHeader(name string, data []byte) []int {
+	pattern := regexp.MustCompile(`(?m)^[ \t]*profile[ \t]+` + regexp.QuoteMeta(name) + `((?:\s+flags\s*=\s*\([^)]*\))?)\s*\{`)
+	return pattern.FindSubmatchIndex(data)
+}
+
+// checkProfileHeader requires the profile to declare the name it is stored with.
+// the kernel loads profiles by the declared name, so a mismatch would confine containers with another profile
+func checkProfileHeader(name string, data []byte) error {
+	if profileHeader(name, data) == nil {
+		return fmt.Errorf("apparmor profile must declare \"profile %s {\"", name)
+	}
+	return nil
+}
+
+// checkProfileContent requires the file to declare only the profile itself, without child profiles or hats,
+// and to include only the apparmor abstractions and tunables
+func checkProfileContent(name string, data []byte) error {
+	declared := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		// 1. include: <abstractions/...> or <tunables/...> only
+		if match := appArmorIncludePattern.FindStringSubmatch(line); match != nil {
+			if err := checkProfileInclude(strings.TrimSpace(match[1])); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 2. declarations. comments are stripped before matching
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if strings.HasPrefix(line, "@{") && strings.Contains(line, "=") {
+			// variable assignment. e.g. @{HOME}={/home/*/,/root/}
+			continue
+		}
+		match := appArmorDeclarationPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if strings.Trim(match[1], `"`) != name {
+			return fmt.Errorf("apparmor profile must not declare other profiles: %s", line)
+		}
+		declared++
+	}
+	if declared != 1 {
+		return fmt.Errorf("apparmor profile must declare \"profile %s {\" exactly once", name)
+	}
+	return nil
+}
+
+func checkProfileInclude(target string) error {
+	if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+		return fmt.Errorf("apparmor profile include must be <abstractions/...> or <tunables/...>: %s", target)
+	}
+	path := strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+	dir, _, _ := strings.Cut(path, "/")
+	if !slices.Contains(appArmorIncludeDirs, dir) || filepath.Clean(path) != path || strings.Contains(path, "..") {
+		return fmt.Errorf("apparmor profile include must be <abstractions/...> or <tunables/...>: %s", target)
+	}
+	return nil
+}
+
+func profileFlags(name string, data []byte) []string {
+	loc := profileHeader(name, data)
+	if loc == nil {
+		return nil
+	}
+	match := appArmorFlagsPattern.FindSubmatch(data[loc[2]:loc[3]])
+	if match == nil {
+		return nil
+	}
+	return strings.FieldsFunc(string(match[1]), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+func profileMode(name string, data []byte) string {
+	if slices.Contains(profileFlags(name, data), AppArmorModeComplain) {
+		return AppArmorModeComplain
+	}
+	return AppArmorModeEnforce
+}
+
+// setProfileMode replaces the mode flag of the profile header. other flags are kept
+func setProfileMode(name string, data []byte, mode string) ([]byte, error) {
+	loc := profileHeader(name, data)
+	if loc == nil {
+		return nil, fmt.Errorf("apparmor profile must declare \"profile %s {\"", name)
+	}
+
+	var flags []string
+	for _, f := range profileFlags(name, data) {
+		if f != AppArmorModeEnforce && f != AppArmorModeComplain {
+			flags = append(flags, f)
+		}
+	}
+	if mode == AppArmorModeComplain {
+		flags = append(flags, AppArmorModeComplain)
+	}
+
+	var replaced string
+	if len(flags) > 0 {
+		replaced = " flags=(" + strings.Join(flags, ",") + ")"
+	}
+	return slices.Concat(data[:loc[2]], []byte(replaced), data[loc[3]:]), nil
+}
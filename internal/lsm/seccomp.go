@@ -0,0 +1,419 @@
+package lsm
+
+import (
+	"bytes"
+	"condenser/internal/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// seccomp profile: raind-default
+// syscalls not listed return EPERM. clone with namespace flags is denied, clone3 returns ENOSYS so libc falls back to clone
+const raindDefaultSeccompProfile = `{
+  "defaultAction": "SCMP_ACT_ERRNO",
+  "defaultErrnoRet": 1,
+  "architectures": [
+    "SCMP_ARCH_X86_64",
+    "SCMP_ARCH_X86",
+    "SCMP_ARCH_X32",
+    "SCMP_ARCH_AARCH64",
+    "SCMP_ARCH_ARM"
+  ],
+  "syscalls": [
+    {
+      "names": [
+        "_llseek", "_newselect", "accept", "accept4", "access", "adjtimex", "alarm", "arch_prctl",
+        "arm_fadvise64_64", "arm_sync_file_range", "bind", "breakpoint", "brk", "cacheflush",
+        "cachestat", "capget", "capset", "chdir", "chmod", "chown", "chown32", "chroot",
+        "clock_adjtime", "clock_adjtime64", "clock_getres", "clock_getres_time64", "clock_gettime",
+        "clock_gettime64", "clock_nanosleep", "clock_nanosleep_time64", "close", "close_range",
+        "connect", "copy_file_range", "creat", "dup", "dup2", "dup3", "epoll_create",
+        "epoll_create1", "epoll_ctl", "epoll_ctl_old", "epoll_pwait", "epoll_pwait2", "epoll_wait",
+        "epoll_wait_old", "eventfd", "eventfd2", "execve", "execveat", "exit", "exit_group",
+        "faccessat", "faccessat2", "fadvise64", "fadvise64_64", "fallocate", "fanotify_mark",
+        "fchdir", "fchmod", "fchmodat", "fchmodat2", "fchown", "fchown32", "fchownat", "fcntl",
+        "fcntl64", "fdatasync", "fgetxattr", "flistxattr", "flock", "fork", "fremovexattr",
+        "fsetxattr", "fstat", "fstat64", "fstatat64", "fstatfs", "fstatfs64", "fsync", "ftruncate",
+        "ftruncate64", "futex", "futex_requeue", "futex_time64", "futex_wait", "futex_waitv",
+        "futex_wake", "futimesat", "get_mempolicy", "get_robust_list", "get_thread_area", "getcpu",
+        "getcwd", "getdents", "getdents64", "getegid", "getegid32", "geteuid", "geteuid32",
+        "getgid", "getgid32", "getgroups", "getgroups32", "getitimer", "getpeername", "getpgid",
+        "getpgrp", "getpid", "getppid", "getpriority", "getrandom", "getresgid", "getresgid32",
+        "getresuid", "getresuid32", "getrlimit", "getrusage", "getsid", "getsockname",
+        "getsockopt", "gettid", "gettimeofday", "getuid", "getuid32", "getxattr",
+        "inotify_add_watch", "inotify_init", "inotify_init1", "inotify_rm_watch", "io_cancel",
+        "io_destroy", "io_getevents", "io_pgetevents", "io_pgetevents_time64", "io_setup",
+        "io_submit", "ioctl", "ioprio_get", "ioprio_set", "ipc", "kill", "landlock_add_rule",
+        "landlock_create_ruleset", "landlock_restrict_self", "lchown", "lchown32", "lgetxattr",
+        "link", "linkat", "listen", "listxattr", "llistxattr", "lremovexattr", "lseek",
+        "lsetxattr", "lstat", "lstat64", "madvise", "map_shadow_stack", "membarrier",
+        "memfd_create", "memfd_secret", "mincore", "mkdir", "mkdirat", "mknod", "mknodat", "mlock",
+        "mlock2", "mlockall", "mmap", "mmap2", "modify_ldt", "mprotect", "mq_getsetattr",
+        "mq_notify", "mq_open", "mq_timedreceive", "mq_timedreceive_time64", "mq_timedsend",
+        "mq_timedsend_time64", "mq_unlink", "mremap", "mseal", "msgctl", "msgget", "msgrcv",
+        "msgsnd", "msync", "munlock", "munlockall", "munmap", "name_to_handle_at", "nanosleep",
+        "newfstatat", "open", "openat", "openat2", "pause", "pidfd_open", "pidfd_send_signal",
+        "pipe", "pipe2", "pkey_alloc", "pkey_free", "pkey_mprotect", "poll", "ppoll",
+        "ppoll_time64", "prctl", "pread64", "preadv", "preadv2", "prlimit64", "process_mrelease",
+        "process_vm_readv", "process_vm_writev", "pselect6", "pselect6_time64", "ptrace",
+        "pwrite64", "pwritev", "pwritev2", "read", "readahead",
+        "readlink", "readlinkat", "readv", "recv", "recvfrom", "recvmmsg", "recvmmsg_time64",
+        "recvmsg", "remap_file_pages", "removexattr", "rename", "renameat", "renameat2",
+        "restart_syscall", "rmdir", "rseq", "rt_sigaction", "rt_sigpending", "rt_sigprocmask",
+        "rt_sigqueueinfo", "rt_sigreturn", "rt_sigsuspend", "rt_sigtimedwait",
+        "rt_sigtimedwait_time64", "rt_tgsigqueueinfo", "sched_get_priority_max",
+        "sched_get_priority_min", "sched_getaffinity", "sched_getattr", "sched_getparam",
+        "sched_getscheduler", "sched_rr_get_interval", "sched_rr_get_interval_time64",
+        "sched_setaffinity", "sched_setattr", "sched_setparam", "sched_setscheduler",
+        "sched_yield", "seccomp", "select", "semctl", "semget", "semop", "semtimedop",
+        "semtimedop_time64", "send", "sendfile", "sendfile64", "sendmmsg", "sendmsg", "sendto",
+        "set_robust_list", "set_thread_area", "set_tid_address", "set_tls", "setfsgid",
+        "setfsgid32", "setfsuid", "setfsuid32", "setgid", "setgid32", "setgroups", "setgroups32",
+        "setitimer", "setpgid", "setpriority", "setregid", "setregid32", "setresgid",
+        "setresgid32", "setresuid", "setresuid32", "setreuid", "setreuid32", "setrlimit", "setsid",
+        "setsockopt", "setuid", "setuid32", "setxattr", "shmat", "shmctl", "shmdt", "shmget",
+        "shutdown", "sigaltstack", "signalfd", "signalfd4", "sigprocmask", "sigreturn", "socket",
+        "socketcall", "socketpair", "splice", "stat", "stat64", "statfs", "statfs64", "statx",
+        "symlink", "symlinkat", "sync", "sync_file_range", "sync_file_range2", "syncfs", "sysinfo",
+        "tee", "tgkill", "time", "timer_create", "timer_delete", "timer_getoverrun",
+        "timer_gettime", "timer_gettime64", "timer_settime", "timer_settime64", "timerfd_create",
+        "timerfd_gettime", "timerfd_gettime64", "timerfd_settime", "timerfd_settime64", "times",
+        "tkill", "truncate", "truncate64", "ugetrlimit", "umask", "uname", "unlink", "unlinkat",
+        "utime", "utimensat", "utimensat_time64", "utimes", "vfork", "vmsplice", "wait4", "waitid",
+        "waitpid", "write", "writev"
+      ],
+      "action": "SCMP_ACT_ALLOW"
+    },
+    {
+      "names": [
+        "personality"
+      ],
+      "action": "SCMP_ACT_ALLOW",
+      "args": [
+        {
+          "index": 0,
+          "value": 0,
+          "op": "SCMP_CMP_EQ"
+        }
+      ]
+    },
+    {
+      "names": [
+        "personality"
+      ],
+      "action": "SCMP_ACT_ALLOW",
+      "args": [
+        {
+          "index": 0,
+          "value": 8,
+          "op": "SCMP_CMP_EQ"
+        }
+      ]
+    },
+    {
+      "names": [
+        "personality"
+      ],
+      "action": "SCMP_ACT_ALLOW",
+      "args": [
+        {
+          "index": 0,
+          "value": 131072,
+          "op": "SCMP_CMP_EQ"
+        }
+      ]
+    },
+    {
+      "names": [
+        "personality"
+      ],
+      "action": "SCMP_ACT_ALLOW",
+      "args": [
+        {
+          "index": 0,
+          "value": 131080,
+          "op": "SCMP_CMP_EQ"
+        }
+      ]
+    },
+    {
+      "names": [
+        "personality"
+      ],
+      "action": "SCMP_ACT_ALLOW",
+      "args": [
+        {
+          "index": 0,
+          "value": 4294967295,
+          "op": "SCMP_CMP_EQ"
+        }
+      ]
+    },
+    {
+      "names": [
+        "clone"
+      ],
+      "action": "SCMP_ACT_ALLOW",
+      "args": [
+        {
+          "index": 0,
+          "value": 2114060288,
+          "op": "SCMP_CMP_MASKED_EQ"
+        }
+      ]
+    },
+    {
+      "names": [
+        "clone3"
+      ],
+      "action": "SCMP_ACT_ERRNO",
+      "errnoRet": 38
+    }
+  ]
+}`
+
+const (
+	SeccompDir            = "/etc/raind/lsm/seccomp"
+	SeccompDefaultProfile = "raind-default"
+	// disables seccomp for the container
+	SeccompUnconfined = "unconfined"
+)
+
+var seccompProfileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var (
+	seccompActions = []string{
+		"SCMP_ACT_KILL", "SCMP_ACT_KILL_PROCESS", "SCMP_ACT_KILL_THREAD", "SCMP_ACT_TRAP",
+		"SCMP_ACT_ERRNO", "SCMP_ACT_TRACE", "SCMP_ACT_ALLOW", "SCMP_ACT_LOG", "SCMP_ACT_NOTIFY",
+	}
+	seccompOperators = []string{
+		"SCMP_CMP_NE", "SCMP_CMP_LT", "SCMP_CMP_LE", "SCMP_CMP_EQ", "SCMP_CMP_GE", "SCMP_CMP_GT", "SCMP_CMP_MASKED_EQ",
+	}
+	seccompArchitectures = []string{
+		"SCMP_ARCH_X86", "SCMP_ARCH_X86_64", "SCMP_ARCH_X32", "SCMP_ARCH_ARM", "SCMP_ARCH_AARCH64",
+		"SCMP_ARCH_MIPS", "SCMP_ARCH_MIPS64", "SCMP_ARCH_MIPS64N32", "SCMP_ARCH_MIPSEL", "SCMP_ARCH_MIPSEL64",
+		"SCMP_ARCH_MIPSEL64N32", "SCMP_ARCH_PPC", "SCMP_ARCH_PPC64", "SCMP_ARCH_PPC64LE", "SCMP_ARCH_S390",
+		"SCMP_ARCH_S390X", "SCMP_ARCH_PARISC", "SCMP_ARCH_PARISC64", "SCMP_ARCH_RISCV64",
+	}
+	seccompFlags = []string{
+		"SECCOMP_FILTER_FLAG_TSYNC", "SECCOMP_FILTER_FLAG_LOG", "SECCOMP_FILTER_FLAG_SPEC_ALLOW",
+		"SECCOMP_FILTER_FLAG_WAIT_KILLABLE_RECV",
+	}
+)
+
+// SeccompProfile is the seccomp section of the OCI runtime spec
+type SeccompProfile struct {
+	DefaultAction    string           `json:"defaultAction"`
+	DefaultErrnoRet  *uint            `json:"defaultErrnoRet,omitempty"`
+	Architectures    []string         `json:"architectures,omitempty"`
+	Flags            []string         `json:"flags,omitempty"`
+	ListenerPath     string           `json:"listenerPath,omitempty"`
+	ListenerMetadata string           `json:"listenerMetadata,omitempty"`
+	Syscalls         []SeccompSyscall `json:"syscalls,omitempty"`
+}
+
+type SeccompSyscall struct {
+	Names    []string     `json:"names"`
+	Action   string       `json:"action"`
+	ErrnoRet *uint        `json:"errnoRet,omitempty"`
+	Args     []SeccompArg `json:"args,omitempty"`
+}
+
+type SeccompArg struct {
+	Index    uint   `json:"index"`
+	Value    uint64 `json:"value"`
+	ValueTwo uint64 `json:"valueTwo,omitempty"`
+	Op       string `json:"op"`
+}
+
+type SeccompHandler interface {
+	EnsureRaindDefaultSeccompProfile() error
+	StoreProfile(name string, data []byte) error
+	RemoveProfile(name string) error
+	GetProfile(name string) (SeccompProfile, error)
+	GetProfilePath(name string) (string, error)
+	GetProfileList() ([]string, error)
+}
+
+func NewSeccompManager() *SeccompManager {
+	return &SeccompManager{
+		filesystemHandler: utils.NewFilesystemExecutor(),
+	}
+}
+
+type SeccompManager struct {
+	filesystemHandler utils.FilesystemHandler
+}
+
+// EnsureRaindDefaultSeccompProfile writes /etc/raind/lsm/seccomp/raind-default.json.
+// the file is rewritten on every bootstrap so the built-in profile follows the binary
+func (m *SeccompManager) EnsureRaindDefaultSeccompProfile() error {
+	if _, err := ParseSeccompProfile([]byte(raindDefaultSeccompProfile)); err != nil {
+		return fmt.Errorf("built-in seccomp profile broken: %w", err)
+	}
+	return m.writeFileAtomic(m.profilePath(SeccompDefaultProfile), []byte(raindDefaultSeccompProfile+"\n"), 0o644)
+}
+
+// StoreProfile validates and writes a custom profile. the built-in profile is not overwritten
+func (m *SeccompManager) StoreProfile(name string, data []byte) error {
+	if err := validateSeccompProfileName(name); err != nil {
+		return err
+	}
+	if name == SeccompDefaultProfile {
+		return fmt.Errorf("seccomp profile: %s is built-in", name)
+	}
+	profile, err := ParseSeccompProfile(data)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+	return m.writeFileAtomic(m.profilePath(name), append(b, '\n'), 0o644)
+}
+
+func (m *SeccompManager) RemoveProfile(name string) error {
+	if err := validateSeccompProfileName(name); err != nil {
+		return err
+	}
+	if name == SeccompDefaultProfile {
+		return fmt.Errorf("seccomp profile: %s is built-in", name)
+	}
+	if err := m.filesystemHandler.Remove(m.profilePath(name)); err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return fmt.Errorf("seccomp profile: %s not found", name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *SeccompManager) GetProfile(name string) (SeccompProfile, error) {
+	if err := validateSeccompProfileName(name); err != nil {
+		return SeccompProfile{}, err
+	}
+	b, err := m.filesystemHandler.ReadFile(m.profilePath(name))
+	if err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return SeccompProfile{}, fmt.Errorf("seccomp profile: %s not found", name)
+		}
+		return SeccompProfile{}, err
+	}
+	profile, err := ParseSeccompProfile(b)
+	if err != nil {
+		return SeccompProfile{}, fmt.Errorf("seccomp profile: %s: %w", name, err)
+	}
+	return profile, nil
+}
+
+// GetProfilePath validates the stored profile and returns its path
+func (m *SeccompManager) GetProfilePath(name string) (string, error) {
+	if _, err := m.GetProfile(name); err != nil {
+		return "", err
+	}
+	return m.profilePath(name), nil
+}
+
+func (m *SeccompManager) GetProfileList() ([]string, error) {
+	entries, err := m.filesystemHandler.ReadDir(SeccompDir)
+	if err != nil {
+		if m.filesystemHandler.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() || !seccompProfileNamePattern.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (m *SeccompManager) profilePath(name string) string {
+	return filepath.Join(SeccompDir, name+".json")
+}
+
+func (m *SeccompManager) writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := m.filesystemHandler.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := m.filesystemHandler.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := m.filesystemHandler.Rename(tmp, path); err != nil {
+		_ = m.filesystemHandler.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func validateSeccompProfileName(name string) error {
+	if !seccompProfileNamePattern.MatchString(name) || name == SeccompUnconfined {
+		return fmt.Errorf("invalid seccomp profile name: %q", name)
+	}
+	return nil
+}
+
+// ParseSeccompProfile decodes an OCI seccomp profile.
+// unknown fields, actions, operators, architectures and syscall names are rejected
+func ParseSeccompProfile(data []byte) (SeccompProfile, error) {
+	var profile SeccompProfile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&profile); err != nil {
+		return SeccompProfile{}, fmt.Errorf("invalid seccomp profile: %w", err)
+	}
+
+	// 1. default action, architectures and flags
+	if !slices.Contains(seccompActions, profile.DefaultAction) {
+		return SeccompProfile{}, fmt.Errorf("invalid defaultAction: %q", profile.DefaultAction)
+	}
+	for _, arch := range profile.Architectures {
+		if !slices.Contains(seccompArchitectures, arch) {
+			return SeccompProfile{}, fmt.Errorf("unknown architecture: %s", arch)
+		}
+	}
+	for _, flag := range profile.Flags {
+		if !slices.Contains(seccompFlags, flag) {
+			return SeccompProfile{}, fmt.Errorf("unknown flag: %s", flag)
+		}
+	}
+
+	// 2. syscall rules
+	for i, rule := range profile.Syscalls {
+		if len(rule.Names) == 0 {
+			return SeccompProfile{}, fmt.Errorf("syscalls[%d]: names is empty", i)
+		}
+		for _, name := range rule.Names {
+			if _, found := slices.BinarySearch(knownSyscalls, name); !found {
+				return SeccompProfile{}, fmt.Errorf("syscalls[%d]: unknown syscall: %s", i, name)
+			}
+		}
+		if !slices.Contains(seccompActions, rule.Action) {
+			return SeccompProfile{}, fmt.Errorf("syscalls[%d]: invalid action: %q", i, rule.Action)
+		}
+		for _, arg := range rule.Args {
+			if arg.Index > 5 {
+				return SeccompProfile{}, fmt.Errorf("syscalls[%d]: invalid arg index: %d", i, arg.Index)
+			}
+			if !slices.Contains(seccompOperators, arg.Op) {
+				return SeccompProfile{}, fmt.Errorf("syscalls[%d]: invalid operator: %q", i, arg.Op)
+			}
+		}
+	}
+	return profile, nil
+}
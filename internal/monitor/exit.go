@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"condenser/internal/store/csm"
+	"condenser/internal/utils"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// zombie children which are not container init processes are reaped after this period.
+// processes started by the daemon itself (droplet commands) are waited by their owner within it
+const orphanReapGrace = 5 * time.Second
+
+// EnableSubreaper marks the daemon as child subreaper.
+// container init processes are re-parented to the daemon when droplet exits,
+// so that the monitor can collect their exit status with wait4
+func EnableSubreaper() error {
+	return unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0)
+}
+
+// runOrphanReaper reaps the other processes re-parented to the daemon (droplet's intermediate forks, exec helpers).
+// wait4(-1) is not used: it would steal the exit status of droplet commands waited by os/exec
+func (m *ContainerMonitor) runOrphanReaper() {
+	sigch := make(chan os.Signal, 1)
+	signal.Notify(sigch, unix.SIGCHLD)
+	ticker := time.NewTicker(orphanReapGrace)
+	defer ticker.Stop()
+
+	firstSeen := map[int]time.Time{}
+	for {
+		select {
+		case <-sigch:
+		case <-ticker.C:
+		}
+		m.reapOrphans(firstSeen)
+	}
+}
+
+func (m *ContainerMonitor) reapOrphans(firstSeen map[int]time.Time) {
+	zombies := m.zombieChildren()
+
+	// container init processes are reaped by reapContainer with their exit status
+	containerPids := map[int]bool{}
+	if containerList, err := m.csmHandler.GetContainerList(); err == nil {
+		for _, c := range containerList {
+			containerPids[c.Pid] = true
+		}
+	}
+
+	now := time.Now()
+	for pid := range firstSeen {
+		if !zombies[pid] {
+			delete(firstSeen, pid)
+		}
+	}
+	for pid := range zombies {
+		if containerPids[pid] {
+			continue
+		}
+		seen, ok := firstSeen[pid]
+		if !ok {
+			firstSeen[pid] = now
+			continue
+		}
+		if now.Sub(seen) < orphanReapGrace {
+			continue
+		}
+		var ws unix.WaitStatus
+		_, _ = unix.Wait4(pid, &ws, unix.WNOHANG, nil)
+		delete(firstSeen, pid)
+	}
+}
+
+// zombieChildren lists the children of the daemon in zombie state from /proc/<pid>/stat
+func (m *ContainerMonitor) zombieChildren() map[int]bool {
+	zombies := map[int]bool{}
+	entries, err := m.filesystemHandler.ReadDir("/proc")
+	if err != nil {
+		return zombies
+	}
+	self := os.Getpid()
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		b, err := m.filesystemHandler.ReadFile(filepath.Join("/proc", e.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// "pid (comm) state ppid ...". comm may contain spaces and parentheses
+		i := strings.LastIndexByte(string(b), ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(string(b[i+1:]))
+		if len(fields) < 2 || fields[0] != "Z" {
+			continue
+		}
+		if ppid, err := strconv.Atoi(fields[1]); err == nil && ppid == self {
+			zombies[pid] = true
+		}
+	}
+	return zombies
+}
+
+// reapContainer checks the container init process.
+// if the process exited, it returns true with the exit status
+func (m *ContainerMonitor) reapContainer(containerId string, pid int) (bool, csm.ExitStatus) {
+	if pid <= 0 {
+		// process not exist
+		return true, m.unknownExitStatus(containerId)
+	}
+
+	var ws unix.WaitStatus
+	wpid, err := unix.Wait4(pid, &ws, unix.WNOHANG, nil)
+	switch {
+	case err == nil && wpid == 0:
+		// child is still running
+		return false, csm.ExitStatus{}
+	case err == nil && wpid == pid:
+		return true, m.buildExitStatus(containerId, ws)
+	case err == unix.ECHILD:
+		// not a child of the daemon (e.g. started by previous daemon). exit status is not available
+		if alive, _ := m.pidAlive(pid); alive {
+			return false, csm.ExitStatus{}
+		}
+		return true, m.unknownExitStatus(containerId)
+	default:
+		// EINTR etc. retry next tick
+		return false, csm.ExitStatus{}
+	}
+}
+
+func (m *ContainerMonitor) buildExitStatus(containerId string, ws unix.WaitStatus) csm.ExitStatus {
+	oomKilled, oomKillCount := m.checkOomKilled(containerId)
+
+	var exitStatus csm.ExitStatus
+	switch {
+	case ws.Exited():
+		code := ws.ExitStatus()
+		exitStatus.ExitCode = &code
+		exitStatus.FinishedReason = "exited"
+	case ws.Signaled():
+		// shell convention: 128 + signal number
+		code := 128 + int(ws.Signal())
+		exitStatus.ExitCode = &code
+		exitStatus.ExitSignal = unix.SignalName(ws.Signal())
+		exitStatus.FinishedReason = "signaled"
+	default:
+		exitStatus.FinishedReason = "unknown"
+	}
+	if oomKilled {
+		exitStatus.FinishedReason = "oom-killed"
+	}
+	exitStatus.OomKilled = oomKilled
+	exitStatus.OomKillCount = oomKillCount
+	return exitStatus
+}
+
+func (m *ContainerMonitor) unknownExitStatus(containerId string) csm.ExitStatus {
+	oomKilled, oomKillCount := m.checkOomKilled(containerId)
+	exitStatus := csm.ExitStatus{
+		FinishedReason: "unknown",
+		OomKilled:      oomKilled,
+		OomKillCount:   oomKillCount,
+	}
+	if oomKilled {
+		exitStatus.FinishedReason = "oom-killed"
+	}
+	return exitStatus
+}
+
+// checkOomKilled compares oom_kill counter in memory.events with the value at the last exit
+func (m *ContainerMonitor) checkOomKilled(containerId string) (bool, int) {
+	containerInfo, err := m.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return false, 0
+	}
+	b, err := m.filesystemHandler.ReadFile(filepath.Join(utils.CgroupRuntimeDir, containerId, "memory.events"))
+	if err != nil {
+		return false, containerInfo.OomKillCount
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "oom_kill" {
+			continue
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil {
+			break
+		}
+		return count > containerInfo.OomKillCount, count
+	}
+	return false, containerInfo.OomKillCount
+}
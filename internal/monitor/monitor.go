@@ -1,29 +1,62 @@
 package monitor
 
 import (
+	"condenser/internal/api/http/logger"
+	"condenser/internal/core/container"
 	"condenser/internal/store/csm"
 	"condenser/internal/utils"
 	"context"
 	"log"
-	"path/filepath"
-	"sync/atomic"
+	"os"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
+)
+
+const (
+	// restart backoff: 500ms, 1s, 2s, ... up to 1 minute
+	restartBackoffBase = 500 * time.Millisecond
+	restartBackoffMax  = 1 * time.Minute
+	// if the container was running longer than this window, restart count is reset
+	restartResetWindow = 10 * time.Second
 
-	"github.com/fsnotify/fsnotify"
+	// healthcheck scheduler tick
+	healthcheckTick = 1 * time.Second
 )
 
 func NewContainerMonitor() *ContainerMonitor {
+	node, _ := os.Hostname()
 	return &ContainerMonitor{
-		csmHandler: csm.NewCsmManager(csm.NewCsmStore(utils.CsmStorePath)),
+		csmHandler:        csm.NewCsmManager(csm.NewCsmStore(utils.CsmStorePath)),
+		containerHandler:  container.NewContaierService(),
+		filesystemHandler: utils.NewFilesystemExecutor(),
+		auditLogger:       logger.JsonLineLogger{Out: openAuditLog()},
+		node:              node,
+		restarting:        map[string]bool{},
+		probing:           map[string]bool{},
+		unhealthy:         map[string]bool{},
 	}
 }
 
 type ContainerMonitor struct {
-	csmHandler csm.CsmHandler
+	csmHandler        csm.CsmHandler
+	containerHandler  container.ContainerServiceHandler
+	filesystemHandler utils.FilesystemHandler
+	auditLogger       logger.Logger
+	node              string
+
+	mu         sync.Mutex
+	restarting map[string]bool
+	probing    map[string]bool
+	// stopped by the monitor for unhealthy restart. the exit is not passed to handleExit
+	unhealthy map[string]bool
 }
 
 func (m *ContainerMonitor) Start() error {
+	// restore containers which should be running after daemon boot
+	m.restoreContainers()
+
 	resolver := NewResolver(m.csmHandler)
 	// watch CSM file update
 	go func() {
@@ -34,32 +67,331 @@ func (m *ContainerMonitor) Start() error {
 		}
 	}()
 
+	// run healthcheck probes
+	go m.runHealthchecks()
+
+	// reap processes re-parented by subreaper
+	go m.runOrphanReaper()
+
 	for {
 		time.Sleep(100 * time.Millisecond)
 
 		for _, container := range resolver.ResolveMap {
 			// status check
-			// monitoring target: created, running
-			if container.Status != "running" && container.Status != "created" {
+			// monitoring target: created, running, paused
+			//   stopped with pid is also a target: the state is changed by stopContainer hook before the process is reaped
+			switch container.Status {
+			case "created", "running", "paused":
+			case "stopped":
+				if container.Pid <= 0 {
+					continue
+				}
+			default:
 				continue
 			}
-			// send keep alive
-			procExist, _ := m.pidAlive(container.Pid)
-			// if process is not exist, change state to stopped
-			if !procExist {
-				log.Printf("[*] Container: %s down detected.", container.ContainerId)
-				if err := m.csmHandler.UpdateContainer(
-					container.ContainerId,
-					"stopped",
-					0,
-				); err != nil {
+			// reap the init process or send keep alive
+			exited, exitStatus := m.reapContainer(container.ContainerId, container.Pid)
+			// if process is not exist, change state to stopped with exit status
+			if exited {
+				log.Printf("[*] Container: %s down detected. (reason=%s, exitCode=%s)", container.ContainerId, exitStatus.FinishedReason, formatExitCode(exitStatus.ExitCode))
+				if err := m.csmHandler.UpdateContainerExit(container.ContainerId, exitStatus); err != nil {
+					continue
+				}
+				// start again if the monitor stopped it as unhealthy, otherwise remove container or apply restart policy
+				if m.takeUnhealthyRestart(container.ContainerId) {
+					go m.startUnhealthy(container.ContainerId)
 					continue
 				}
+				m.handleExit(container.ContainerId, false)
+			}
+		}
+	}
+}
+
+func (m *ContainerMonitor) restoreContainers() {
+	containerList, err := m.csmHandler.GetContainerList()
+	if err != nil {
+		log.Printf("restore containers failed: %v", err)
+		return
+	}
+	for _, c := range containerList {
+		switch c.State {
+		case "created", "running", "paused":
+			// the process does not survive host reboot
+			if alive, _ := m.pidAlive(c.Pid); alive {
+				continue
+			}
+			// exit status is lost with the previous daemon
+			if err := m.csmHandler.UpdateContainerExit(c.ContainerId, m.unknownExitStatus(c.ContainerId)); err != nil {
+				continue
+			}
+		case "stopped":
+		default:
+			continue
+		}
+		m.handleExit(c.ContainerId, true)
+	}
+}
+
+// handleExit removes auto remove container, otherwise applies restart policy
+func (m *ContainerMonitor) handleExit(containerId string, boot bool) {
+	containerInfo, err := m.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return
+	}
+	if containerInfo.AutoRemove {
+		go m.autoRemove(containerInfo)
+		return
+	}
+	m.handleRestartPolicy(containerId, boot)
+}
+
+// autoRemove runs the same cleanup as delete operation and records the result to the audit log
+func (m *ContainerMonitor) autoRemove(containerInfo csm.ContainerInfo) {
+	ev := logger.NewEvent("monitor.autoRemove", "condenser-monitor", m.node)
+	ev.Target = logger.Target{
+		ContainerId:   containerInfo.ContainerId,
+		ContainerName: containerInfo.ContainerName,
+	}
+	if containerInfo.ExitCode != nil {
+		ev.Extra["exitCode"] = *containerInfo.ExitCode
+	}
+	ev.Extra["finishedReason"] = containerInfo.FinishedReason
+
+	if _, err := m.containerHandler.Delete(container.ServiceDeleteModel{ContainerId: containerInfo.ContainerId}); err != nil {
+		log.Printf("[*] Container: %s auto remove failed: %v", containerInfo.ContainerId, err)
+		ev.Result = logger.Result{Status: "error", Reasone: err.Error()}
+	} else {
+		log.Printf("[*] Container: %s removed (autoRemove)", containerInfo.ContainerId)
+		ev.Result = logger.Result{Status: "allow"}
+	}
+	m.auditLogger.Write(ev)
+}
+
+// handleRestartPolicy decides whether the stopped container should be restarted.
+// boot is true when called from daemon boot, so that "always" containers are restored
+// even if they were stopped manually.
+func (m *ContainerMonitor) handleRestartPolicy(containerId string, boot bool) {
+	containerInfo, err := m.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return
+	}
+	if !m.shouldRestart(containerInfo, boot) {
+		return
+	}
+
+	// reset restart count if the container was running long enough
+	count := containerInfo.RestartCount
+	if !containerInfo.StartedAt.IsZero() && containerInfo.StoppedAt.Sub(containerInfo.StartedAt) >= restartResetWindow {
+		count = 0
+	}
+
+	m.mu.Lock()
+	if m.restarting[containerId] {
+		m.mu.Unlock()
+		return
+	}
+	m.restarting[containerId] = true
+	m.mu.Unlock()
+
+	go m.restartWithBackoff(containerId, count, boot)
+}
+
+func (m *ContainerMonitor) shouldRestart(containerInfo csm.ContainerInfo, boot bool) bool {
+	switch containerInfo.RestartPolicy.Name {
+	case "always":
+		return boot || !containerInfo.ManuallyStopped
+	case "unless-stopped":
+		return !containerInfo.ManuallyStopped
+	case "on-failure":
+		return !containerInfo.ManuallyStopped && exitedWithFailure(containerInfo)
+	default:
+		return false
+	}
+}
+
+// exitedWithFailure reports whether the last exit is a failure for the on-failure policy:
+// a recorded non-zero exit code or an OOM kill. exit 0 and lost exit status (e.g. host reboot) are not restarted
+func exitedWithFailure(containerInfo csm.ContainerInfo) bool {
+	if containerInfo.OomKilled {
+		return true
+	}
+	return containerInfo.ExitCode != nil && *containerInfo.ExitCode != 0
+}
+
+func (m *ContainerMonitor) restartWithBackoff(containerId string, count int, boot bool) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.restarting, containerId)
+		m.mu.Unlock()
+	}()
+
+	for {
+		time.Sleep(m.restartBackoff(count))
+
+		// re-check current state. the container may be started, stopped or deleted meanwhile
+		containerInfo, err := m.csmHandler.GetContainerById(containerId)
+		if err != nil {
+			return
+		}
+		if containerInfo.State != "stopped" || !m.shouldRestart(containerInfo, boot) {
+			return
+		}
+		maxRetries := containerInfo.RestartPolicy.MaxRetries
+		if containerInfo.RestartPolicy.Name == "on-failure" && maxRetries > 0 && count >= maxRetries {
+			log.Printf("[*] Container: %s reached max restart retries: %d", containerId, maxRetries)
+			return
+		}
+
+		count++
+		if err := m.csmHandler.UpdateRestartCount(containerId, count); err != nil {
+			return
+		}
+
+		// restart: same path as start operation for stopped container
+		log.Printf("[*] Container: %s restarting (policy=%s, count=%d)", containerId, containerInfo.RestartPolicy.Name, count)
+		if _, err := m.containerHandler.Start(
+			container.ServiceStartModel{
+				ContainerId: containerId,
+				Tty:         containerInfo.Tty,
+			},
+		); err != nil {
+			log.Printf("[*] Container: %s restart failed: %v", containerId, err)
+			continue
+		}
+		return
+	}
+}
+
+func (m *ContainerMonitor) restartBackoff(count int) time.Duration {
+	backoff := restartBackoffBase
+	for i := 0; i < count; i++ {
+		backoff *= 2
+		if backoff >= restartBackoffMax {
+			return restartBackoffMax
+		}
+	}
+	return backoff
+}
+
+func (m *ContainerMonitor) runHealthchecks() {
+	for {
+		time.Sleep(healthcheckTick)
+
+		containerList, err := m.csmHandler.GetContainerList()
+		if err != nil {
+			continue
+		}
+		for _, c := range containerList {
+			if c.State != "running" || len(c.Healthcheck.Test) == 0 {
+				continue
+			}
+			// first probe runs one interval after start
+			last := c.Health.LastCheckedAt
+			if last.Before(c.StartedAt) {
+				last = c.StartedAt
 			}
+			if time.Since(last) < c.Healthcheck.Interval {
+				continue
+			}
+
+			m.mu.Lock()
+			if m.probing[c.ContainerId] {
+				m.mu.Unlock()
+				continue
+			}
+			m.probing[c.ContainerId] = true
+			m.mu.Unlock()
+
+			go m.probe(c)
 		}
 	}
 }
 
+func (m *ContainerMonitor) probe(containerInfo csm.ContainerInfo) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.probing, containerInfo.ContainerId)
+		m.mu.Unlock()
+	}()
+
+	health, err := m.containerHandler.Healthcheck(container.ServiceHealthcheckModel{ContainerId: containerInfo.ContainerId})
+	if err != nil {
+		log.Printf("[*] Container: %s healthcheck failed: %v", containerInfo.ContainerId, err)
+		return
+	}
+
+	restartAfter := containerInfo.Healthcheck.RestartAfter
+	if restartAfter > 0 && health.FailingStreak >= restartAfter {
+		m.restartUnhealthy(containerInfo)
+	}
+}
+
+// restartUnhealthy stops the container. the monitor starts it again when the exit is observed,
+// so the restart does not race with auto remove or the restart policy
+func (m *ContainerMonitor) restartUnhealthy(containerInfo csm.ContainerInfo) {
+	containerId := containerInfo.ContainerId
+
+	m.mu.Lock()
+	if m.unhealthy[containerId] || m.restarting[containerId] {
+		m.mu.Unlock()
+		return
+	}
+	m.unhealthy[containerId] = true
+	m.mu.Unlock()
+
+	log.Printf("[*] Container: %s unhealthy, restarting", containerId)
+	if _, err := m.containerHandler.Stop(container.ServiceStopModel{ContainerId: containerId}); err != nil {
+		log.Printf("[*] Container: %s stop failed: %v", containerId, err)
+		m.takeUnhealthyRestart(containerId)
+	}
+}
+
+func (m *ContainerMonitor) takeUnhealthyRestart(containerId string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.unhealthy[containerId] {
+		return false
+	}
+	delete(m.unhealthy, containerId)
+	return true
+}
+
+// startUnhealthy starts the container stopped by restartUnhealthy.
+// if start fails, the exit is handled as a normal exit
+func (m *ContainerMonitor) startUnhealthy(containerId string) {
+	containerInfo, err := m.csmHandler.GetContainerById(containerId)
+	if err != nil {
+		return
+	}
+	if _, err := m.containerHandler.Start(
+		container.ServiceStartModel{
+			ContainerId: containerId,
+			Tty:         containerInfo.Tty,
+		},
+	); err != nil {
+		log.Printf("[*] Container: %s restart failed: %v", containerId, err)
+		// the stop was not requested by the user
+		_ = m.csmHandler.UpdateManuallyStopped(containerId, false)
+		m.handleExit(containerId, false)
+	}
+}
+
+func formatExitCode(code *int) string {
+	if code == nil {
+		return "unknown"
+	}
+	return strconv.Itoa(*code)
+}
+
+func openAuditLog() *os.File {
+	fd, err := os.OpenFile(utils.AuditLogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		log.Fatal("open audit log file failed")
+	}
+	return fd
+}
+
 func (m *ContainerMonitor) pidAlive(pid int) (bool, error) {
 	if pid <= 0 {
 		// process not exist
@@ -125,42 +457,19 @@ func (r *Resolver) Refresh() {
 }
 
 func (r *Resolver) Watch(ctx context.Context) error {
-	w, err := fsnotify.NewWatcher()
+	notify, err := csm.Watch(ctx, utils.CsmStorePath)
 	if err != nil {
 		return err
 	}
-	defer w.Close()
-
-	dir := filepath.Dir(utils.CsmStorePath)
-	base := filepath.Base(utils.CsmStorePath)
-
-	if err := w.Add(dir); err != nil {
-		return err
-	}
-
-	var pending atomic.Bool
-	trigger := func() {
-		if pending.CompareAndSwap(false, true) {
-			go func() {
-				time.Sleep(50 * time.Millisecond)
-				r.Refresh()
-				pending.Store(false)
-			}()
-		}
-	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case ev := <-w.Events:
-			if filepath.Base(ev.Name) != base {
-				continue
-			}
-			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
-				trigger()
-			}
-		case <-w.Errors:
+		case <-notify:
+			// wait for successive writes
+			time.Sleep(50 * time.Millisecond)
+			r.Refresh()
 		}
 	}
 }
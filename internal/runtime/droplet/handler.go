@@ -1,11 +1,15 @@
 package droplet
 
 import (
+	"bytes"
 	"condenser/internal/runtime"
 	"condenser/internal/utils"
 	"fmt"
+	"regexp"
 	"slices"
 	"strings"
+	"syscall"
+	"time"
 )
 
 func NewDropletHandler() *DropletHandler {
@@ -20,6 +24,9 @@ type DropletHandler struct {
 
 const runtimePath = "droplet"
 
+// signal sent by droplet kill without --signal
+const defaultKillSignal = "SIGTERM"
+
 func (h *DropletHandler) Spec(specParameter runtime.SpecModel) error {
 	args := []string{
 		"spec",
@@ -36,6 +43,9 @@ func (h *DropletHandler) Spec(specParameter runtime.SpecModel) error {
 		"--work_dir", specParameter.WorkDir,
 		"--output", specParameter.Output,
 	}
+	if specParameter.User != "" {
+		args = slices.Concat(args, []string{"--user", specParameter.User})
+	}
 	for _, v := range specParameter.Namespace {
 		args = slices.Concat(args, []string{"--ns", v})
 	}
@@ -45,6 +55,24 @@ func (h *DropletHandler) Spec(specParameter runtime.SpecModel) error {
 	for _, v := range specParameter.Mount {
 		args = slices.Concat(args, []string{"--mount", v})
 	}
+	for _, v := range specParameter.Tmpfs {
+		args = slices.Concat(args, []string{"--tmpfs", v})
+	}
+	if specParameter.ReadOnlyRootfs {
+		args = append(args, "--read_only_rootfs")
+	}
+	for _, v := range specParameter.Capabilities {
+		args = slices.Concat(args, []string{"--cap", v})
+	}
+	if specParameter.NoNewPrivileges {
+		args = append(args, "--no_new_privs")
+	}
+	if specParameter.SeccompProfile != "" {
+		args = slices.Concat(args, []string{"--seccomp", specParameter.SeccompProfile})
+	}
+	if specParameter.AppArmorProfile != "" {
+		args = slices.Concat(args, []string{"--apparmor", specParameter.AppArmorProfile})
+	}
 	for _, v := range specParameter.ContainerDns {
 		args = slices.Concat(args, []string{"--dns", v})
 	}
@@ -162,10 +190,19 @@ func (h *DropletHandler) Delete(deleteParameter runtime.DeleteModel) error {
 }
 
 func (h *DropletHandler) Stop(stopParameter runtime.StopModel) error {
-	args := []string{
-		"kill",
-		stopParameter.ContainerId,
+	args := []string{"kill"}
+	// --signal is not supported by older droplet. pass it only for non-default signals
+	if stopParameter.Signal != "" && stopParameter.Signal != defaultKillSignal {
+		supported, err := h.SupportsOption("kill", "--signal")
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return fmt.Errorf("droplet stop failed: kill --signal is not supported by the installed droplet. upgrade droplet")
+		}
+		args = slices.Concat(args, []string{"--signal", stopParameter.Signal})
 	}
+	args = append(args, stopParameter.ContainerId)
 	runtimeStop := h.commandFactory.Command(runtimePath, args...)
 	out, err := runtimeStop.CombineOutput()
 	if err != nil {
@@ -204,3 +241,53 @@ func (h *DropletHandler) Exec(execParameter runtime.ExecModel) error {
 	}
 	return nil
 }
+
+// ExecOutput runs command in the container and returns combined output.
+// if Timeout is set, droplet exec process is killed after the timeout
+func (h *DropletHandler) ExecOutput(execParameter runtime.ExecModel) ([]byte, error) {
+	args := []string{
+		"exec",
+		execParameter.ContainerId,
+	}
+	args = append(args, execParameter.Entrypoint...)
+	runtimeExec := h.commandFactory.Command(runtimePath, args...)
+
+	var out bytes.Buffer
+	runtimeExec.SetStdout(&out)
+	runtimeExec.SetStderr(&out)
+	if err := runtimeExec.Start(); err != nil {
+		return nil, fmt.Errorf("droplet exec failed: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runtimeExec.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if execParameter.Timeout > 0 {
+		timer := time.NewTimer(execParameter.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case err := <-done:
+		return out.Bytes(), err
+	case <-timeout:
+		_ = syscall.Kill(runtimeExec.Pid(), syscall.SIGKILL)
+		<-done
+		return out.Bytes(), fmt.Errorf("droplet exec timed out after %s", execParameter.Timeout)
+	}
+}
+
+// SupportsOption reports whether the installed droplet lists the option in the help of the subcommand.
+// options added in later droplet releases are checked before use
+func (h *DropletHandler) SupportsOption(subcommand string, option string) (bool, error) {
+	// help may exit with non-zero status. the output is checked instead
+	out, err := h.commandFactory.Command(runtimePath, subcommand, "--help").CombineOutput()
+	if len(out) == 0 && err != nil {
+		return false, fmt.Errorf("droplet %s --help failed: %w", subcommand, err)
+	}
+	return regexp.MustCompile(`(^|[^\w-])` + regexp.QuoteMeta(option) + `\b`).Match(out), nil
+}
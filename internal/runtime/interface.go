@@ -7,4 +7,6 @@ type RuntimeHandler interface {
 	Delete(deleteParameter DeleteModel) error
 	Stop(stopParameter StopModel) error
 	Exec(execParameter ExecModel) error
+	ExecOutput(execParameter ExecModel) ([]byte, error)
+	SupportsOption(subcommand string, option string) (bool, error)
 }
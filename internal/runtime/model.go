@@ -1,13 +1,27 @@
 package runtime
 
+import "time"
+
 type SpecModel struct {
-	Rootfs    string
-	Cwd       string
-	Command   string
+	Rootfs  string
+	Cwd     string
+	Command string
+	// uid:gid. empty runs as root
+	User      string
 	Namespace []string
 	Hostname  string
 	Env       []string
 	Mount     []string
+	// "target:option,option"
+	Tmpfs          []string
+	ReadOnlyRootfs bool
+	// bounding, effective, permitted and inheritable set. e.g. CAP_CHOWN
+	Capabilities    []string
+	NoNewPrivileges bool
+	// path of the OCI seccomp profile json. empty disables seccomp
+	SeccompProfile string
+	// apparmor profile name loaded in the kernel. "unconfined" disables apparmor
+	AppArmorProfile string
 
 	HostInterface          string
 	BridgeInterface        string
@@ -52,10 +66,13 @@ type DeleteModel struct {
 
 type StopModel struct {
 	ContainerId string
+	// signal name delivered to the init process. e.g. SIGTERM
+	Signal string
 }
 
 type ExecModel struct {
 	ContainerId string
 	Entrypoint  []string
 	Tty         bool
+	Timeout     time.Duration
 }
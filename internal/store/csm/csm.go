@@ -15,19 +15,12 @@ type CsmManager struct {
 	csmStore *CsmStore
 }
 
-func (m *CsmManager) StoreContainer(containerId string, state string, pid int, tty bool, repo, ref string, command []string, name string) error {
+func (m *CsmManager) StoreContainer(containerInfo ContainerInfo) error {
 	return m.csmStore.withLock(func(st *ContainerState) error {
-		st.Containers[containerId] = ContainerInfo{
-			ContainerId:   containerId,
-			ContainerName: name,
-			State:         state,
-			Pid:           pid,
-			Tty:           tty,
-			Repository:    repo,
-			Reference:     ref,
-			Command:       command,
-			CreatedAt:     time.Now(),
-		}
+		now := time.Now()
+		containerInfo.Created = now
+		containerInfo.CreatedAt = now
+		st.Containers[containerInfo.ContainerId] = containerInfo
 		return nil
 	})
 }
@@ -51,6 +44,7 @@ func (m *CsmManager) UpdateContainer(containerId string, state string, pid int)
 			return fmt.Errorf("containerId=%s not found", containerId)
 		}
 
+		previous := c.State
 		c.State = state
 		switch state {
 		case "creating":
@@ -58,7 +52,15 @@ func (m *CsmManager) UpdateContainer(containerId string, state string, pid int)
 		case "created":
 			c.CreatedAt = time.Now()
 		case "running":
-			c.StartedAt = time.Now()
+			// unpause does not change started time
+			if previous != "paused" {
+				c.StartedAt = time.Now()
+				// exit status of the previous run
+				c.ExitCode = nil
+				c.FinishedReason = ""
+				c.ExitSignal = ""
+				c.OomKilled = false
+			}
 		case "stopped":
 			c.StoppedAt = time.Now()
 		}
@@ -71,6 +73,26 @@ func (m *CsmManager) UpdateContainer(containerId string, state string, pid int)
 	})
 }
 
+// UpdateContainerExit changes the state to stopped and records the exit status at once
+func (m *CsmManager) UpdateContainerExit(containerId string, exit ExitStatus) error {
+	return m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		c.State = "stopped"
+		c.StoppedAt = time.Now()
+		c.Pid = 0
+		c.ExitCode = exit.ExitCode
+		c.FinishedReason = exit.FinishedReason
+		c.ExitSignal = exit.ExitSignal
+		c.OomKilled = exit.OomKilled
+		c.OomKillCount = exit.OomKillCount
+		st.Containers[containerId] = c
+		return nil
+	})
+}
+
 func (m *CsmManager) UpdateSpiffe(containerId string, spiffe string) error {
 	return m.csmStore.withLock(func(st *ContainerState) error {
 		c, ok := st.Containers[containerId]
@@ -83,6 +105,75 @@ func (m *CsmManager) UpdateSpiffe(containerId string, spiffe string) error {
 	})
 }
 
+func (m *CsmManager) UpdateResources(containerId string, resources ResourceInfo) error {
+	return m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		c.Resources = resources
+		st.Containers[containerId] = c
+		return nil
+	})
+}
+
+func (m *CsmManager) UpdateRestartCount(containerId string, count int) error {
+	return m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		c.RestartCount = count
+		st.Containers[containerId] = c
+		return nil
+	})
+}
+
+func (m *CsmManager) UpdateManuallyStopped(containerId string, stopped bool) error {
+	return m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		c.ManuallyStopped = stopped
+		st.Containers[containerId] = c
+		return nil
+	})
+}
+
+func (m *CsmManager) UpdateHealth(containerId string, health HealthInfo) error {
+	return m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		c.Health = health
+		st.Containers[containerId] = c
+		return nil
+	})
+}
+
+// UpdateHealthProbe applies a probe result to the current health under the lock.
+// the result is discarded when the container was stopped or restarted while probing
+func (m *CsmManager) UpdateHealthProbe(containerId string, startedAt time.Time, apply func(HealthInfo) HealthInfo) (HealthInfo, error) {
+	var health HealthInfo
+	err := m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		if c.State != "running" || !c.StartedAt.Equal(startedAt) {
+			health = c.Health
+			return nil
+		}
+		c.Health = apply(c.Health)
+		health = c.Health
+		st.Containers[containerId] = c
+		return nil
+	})
+	return health, err
+}
+
 func (m *CsmManager) GetContainerList() ([]ContainerInfo, error) {
 	var containerList []ContainerInfo
 	err := m.csmStore.withRLock(func(st *ContainerState) error {
@@ -109,6 +200,27 @@ func (m *CsmManager) GetContainerById(containerId string) (ContainerInfo, error)
 	return containerInfo, err
 }
 
+// RenameContainer changes the container name. the name is checked in the same lock
+func (m *CsmManager) RenameContainer(containerId string, name string) (string, error) {
+	var oldName string
+	err := m.csmStore.withLock(func(st *ContainerState) error {
+		c, ok := st.Containers[containerId]
+		if !ok {
+			return fmt.Errorf("containerId=%s not found", containerId)
+		}
+		for id, other := range st.Containers {
+			if id != containerId && other.ContainerName == name {
+				return fmt.Errorf("name: %s already used by other container", name)
+			}
+		}
+		oldName = c.ContainerName
+		c.ContainerName = name
+		st.Containers[containerId] = c
+		return nil
+	})
+	return oldName, err
+}
+
 func (m *CsmManager) IsNameAlreadyUsed(name string) bool {
 	var result bool
 	_ = m.csmStore.withRLock(func(st *ContainerState) error {
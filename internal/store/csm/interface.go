@@ -1,14 +1,23 @@
 package csm
 
+import "time"
+
 type CsmStoreHandler interface {
 	SetContainerState() error
 }
 
 type CsmHandler interface {
-	StoreContainer(containerId string, state string, pid int, tty bool, repo, ref string, command []string, name string) error
+	StoreContainer(containerInfo ContainerInfo) error
 	RemoveContainer(containerId string) error
 	UpdateContainer(containerId string, state string, pid int) error
 	UpdateSpiffe(containerId string, spiffe string) error
+	UpdateResources(containerId string, resources ResourceInfo) error
+	UpdateRestartCount(containerId string, count int) error
+	UpdateManuallyStopped(containerId string, stopped bool) error
+	UpdateHealth(containerId string, health HealthInfo) error
+	UpdateHealthProbe(containerId string, startedAt time.Time, apply func(HealthInfo) HealthInfo) (HealthInfo, error)
+	UpdateContainerExit(containerId string, exit ExitStatus) error
+	RenameContainer(containerId string, name string) (string, error)
 	GetContainerList() ([]ContainerInfo, error)
 	GetContainerById(containerId string) (ContainerInfo, error)
 	IsNameAlreadyUsed(name string) bool
@@ -12,10 +12,104 @@ type ContainerInfo struct {
 	Repository    string    `json:"imageRepository"`
 	Reference     string    `json:"imageReference"`
 	Command       []string  `json:"command"`
+	Created       time.Time `json:"created"` // set once by StoreContainer, not changed by re-create
 	CreatingAt    time.Time `json:"creatingAt"`
 	CreatedAt     time.Time `json:"createdAt"`
 	StartedAt     time.Time `json:"statedAt"`
 	StoppedAt     time.Time `json:"stoppedAt"`
+
+	User         string            `json:"user,omitempty"`
+	ExposedPorts []string          `json:"exposedPorts,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Dns          DnsInfo           `json:"dns"`
+
+	ReadOnlyRootfs bool        `json:"readOnlyRootfs"`
+	Tmpfs          []TmpfsInfo `json:"tmpfs,omitempty"`
+
+	// effective bounding set
+	Capabilities    []string `json:"capabilities"`
+	NoNewPrivileges bool     `json:"noNewPrivileges"`
+	// seccomp profile name. "unconfined" when disabled
+	SeccompProfile string `json:"seccompProfile,omitempty"`
+	// apparmor profile name. "unconfined" when disabled
+	AppArmorProfile string `json:"appArmorProfile,omitempty"`
+
+	Resources ResourceInfo `json:"resources"`
+
+	RestartPolicy   RestartPolicyInfo `json:"restartPolicy"`
+	RestartCount    int               `json:"restartCount"`
+	ManuallyStopped bool              `json:"manuallyStopped"`
+
+	Healthcheck HealthcheckInfo `json:"healthcheck"`
+	Health      HealthInfo      `json:"health"`
+
+	StopSignal string `json:"stopSignal,omitempty"`
+	AutoRemove bool   `json:"autoRemove"`
+
+	// result of the last exit. exit code is nil until the first exit or when the status is lost
+	ExitCode       *int   `json:"exitCode"`
+	FinishedReason string `json:"finishedReason,omitempty"`
+	ExitSignal     string `json:"exitSignal,omitempty"`
+	OomKilled      bool   `json:"oomKilled"`
+	// oom_kill counter in memory.events observed at the last exit
+	OomKillCount int `json:"oomKillCount"`
+}
+
+type ExitStatus struct {
+	// nil when the exit status is not available
+	ExitCode       *int
+	FinishedReason string
+	ExitSignal     string
+	OomKilled      bool
+	OomKillCount   int
+}
+
+type HealthcheckInfo struct {
+	Test         []string      `json:"test,omitempty"`
+	Interval     time.Duration `json:"interval,omitempty"`
+	Timeout      time.Duration `json:"timeout,omitempty"`
+	StartPeriod  time.Duration `json:"startPeriod,omitempty"`
+	Retries      int           `json:"retries,omitempty"`
+	RestartAfter int           `json:"restartAfter,omitempty"`
+}
+
+type HealthInfo struct {
+	Status        string    `json:"status,omitempty"`
+	FailingStreak int       `json:"failingStreak"`
+	LastOutput    string    `json:"lastOutput,omitempty"`
+	LastCheckedAt time.Time `json:"lastCheckedAt"`
+}
+
+// hostname and resolver configuration written to /etc/hosts, /etc/hostname and /etc/resolv.conf
+type DnsInfo struct {
+	Hostname   string   `json:"hostname"`
+	Domainname string   `json:"domainname,omitempty"`
+	Servers    []string `json:"servers,omitempty"`
+	Search     []string `json:"search,omitempty"`
+	Options    []string `json:"options,omitempty"`
+	// "hostname:ip"
+	ExtraHosts []string `json:"extraHosts,omitempty"`
+}
+
+type TmpfsInfo struct {
+	Target string `json:"target"`
+	Size   string `json:"size,omitempty"`
+	Mode   string `json:"mode,omitempty"`
+	Noexec bool   `json:"noexec,omitempty"`
+}
+
+type RestartPolicyInfo struct {
+	Name       string `json:"name"`
+	MaxRetries int    `json:"maxRetries,omitempty"`
+}
+
+type ResourceInfo struct {
+	MemoryMax     string   `json:"memoryMax,omitempty"`
+	MemorySwapMax string   `json:"memorySwapMax,omitempty"`
+	CpuMax        string   `json:"cpuMax,omitempty"`
+	CpuWeight     int      `json:"cpuWeight,omitempty"`
+	PidsMax       string   `json:"pidsMax,omitempty"`
+	IoMax         []string `json:"ioMax,omitempty"`
 }
 
 type ContainerState struct {
@@ -0,0 +1,54 @@
+package csm
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch notifies update of the CSM store file until ctx is done.
+// notifications are coalesced: a pending notification is not duplicated
+func Watch(ctx context.Context, storePath string) (<-chan struct{}, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(storePath)
+	base := filepath.Base(storePath)
+	if err := w.Add(dir); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	notify := make(chan struct{}, 1)
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(ev.Name) != base {
+					continue
+				}
+				if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return notify, nil
+}
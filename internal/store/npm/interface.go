@@ -23,4 +23,5 @@ type NpmHandler interface {
 
 	UpdateStatus(chainName string, ruleId string, status string, reason string) error
 	ChangeNSMode(mode string) error
+	RenameContainer(oldName string, newName string) error
 }
@@ -201,6 +201,28 @@ func (m *NpmManager) UpdateStatus(chainName string, policyId string, status stri
 	})
 }
 
+// RenameContainer replaces the container name referenced by source/destination of all policies
+func (m *NpmManager) RenameContainer(oldName string, newName string) error {
+	return m.npmStore.withLock(func(np *NetworkPolicy) error {
+		for _, chain := range []*[]Policy{
+			&np.Policies.EastWestPolicy,
+			&np.Policies.NorthSouthObservePolicy,
+			&np.Policies.NorthSouthEnforcePolicy,
+		} {
+			for i, p := range *chain {
+				if p.Source.ContainerName == oldName {
+					p.Source.ContainerName = newName
+				}
+				if p.Destination.ContainerName == oldName {
+					p.Destination.ContainerName = newName
+				}
+				(*chain)[i] = p
+			}
+		}
+		return nil
+	})
+}
+
 func (m *NpmManager) ChangeNSMode(mode string) error {
 	return m.npmStore.withLock(func(np *NetworkPolicy) error {
 		np.DefaultRule.NorthSouth.Mode = mode
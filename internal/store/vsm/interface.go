@@ -0,0 +1,16 @@
+package vsm
+
+type VsmStoreHandler interface {
+	SetVolumeState() error
+}
+
+type VsmHandler interface {
+	StoreVolume(name string, mountpoint string, labels map[string]string) error
+	RemoveVolume(name string, cleanup func(VolumeInfo) error) error
+	AttachContainer(name string, mountpoint string, containerId string) (VolumeInfo, error)
+	MarkPopulated(name string) error
+	DetachContainer(containerId string) error
+	GetVolumeList() ([]VolumeInfo, error)
+	GetVolumeByName(name string) (VolumeInfo, error)
+	IsVolumeExist(name string) bool
+}
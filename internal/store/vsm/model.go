@@ -0,0 +1,19 @@
+package vsm
+
+import "time"
+
+type VolumeInfo struct {
+	Name       string            `json:"name"`
+	Mountpoint string            `json:"mountpoint"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	// ids of the containers mounting the volume
+	Containers []string `json:"containers"`
+	// true after the image content was copied on the first mount. image content is copied only into a new volume
+	Populated bool      `json:"populated"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type VolumeState struct {
+	Version string                `json:"version"`
+	Volumes map[string]VolumeInfo `json:"volumes"`
+}
@@ -0,0 +1,143 @@
+package vsm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrVolumeInUse = errors.New("volume is in use")
+
+func NewVsmManager(vsmStore *VsmStore) *VsmManager {
+	return &VsmManager{
+		vsmStore: vsmStore,
+	}
+}
+
+type VsmManager struct {
+	vsmStore *VsmStore
+}
+
+func (m *VsmManager) StoreVolume(name string, mountpoint string, labels map[string]string) error {
+	return m.vsmStore.withLock(func(st *VolumeState) error {
+		if st.Volumes == nil {
+			st.Volumes = map[string]VolumeInfo{}
+		}
+		if _, ok := st.Volumes[name]; ok {
+			return fmt.Errorf("volume: %s already exists", name)
+		}
+		st.Volumes[name] = VolumeInfo{
+			Name:       name,
+			Mountpoint: mountpoint,
+			Labels:     labels,
+			Containers: []string{},
+			CreatedAt:  time.Now(),
+		}
+		return nil
+	})
+}
+
+// RemoveVolume removes the entry. volumes referenced by containers are not removed.
+// cleanup runs under the store lock before the entry is removed, so that no container attaches the volume meanwhile
+func (m *VsmManager) RemoveVolume(name string, cleanup func(VolumeInfo) error) error {
+	return m.vsmStore.withLock(func(st *VolumeState) error {
+		v, ok := st.Volumes[name]
+		if !ok {
+			return fmt.Errorf("volume: %s not found", name)
+		}
+		if len(v.Containers) > 0 {
+			return fmt.Errorf("%w: %s (containers: %s)", ErrVolumeInUse, name, strings.Join(v.Containers, ", "))
+		}
+		if err := cleanup(v); err != nil {
+			return err
+		}
+		delete(st.Volumes, name)
+		return nil
+	})
+}
+
+// AttachContainer adds the container reference, creating the entry when it does not exist.
+// both are stored in one transaction, so that a volume is never seen unreferenced in between
+func (m *VsmManager) AttachContainer(name string, mountpoint string, containerId string) (VolumeInfo, error) {
+	var volumeInfo VolumeInfo
+	err := m.vsmStore.withLock(func(st *VolumeState) error {
+		if st.Volumes == nil {
+			st.Volumes = map[string]VolumeInfo{}
+		}
+		v, ok := st.Volumes[name]
+		if !ok {
+			v = VolumeInfo{
+				Name:       name,
+				Mountpoint: mountpoint,
+				Containers: []string{},
+				CreatedAt:  time.Now(),
+			}
+		}
+		if !slices.Contains(v.Containers, containerId) {
+			v.Containers = append(v.Containers, containerId)
+		}
+		st.Volumes[name] = v
+		volumeInfo = v
+		return nil
+	})
+	return volumeInfo, err
+}
+
+// MarkPopulated records that the image content was copied into the volume
+func (m *VsmManager) MarkPopulated(name string) error {
+	return m.vsmStore.withLock(func(st *VolumeState) error {
+		v, ok := st.Volumes[name]
+		if !ok {
+			return fmt.Errorf("volume: %s not found", name)
+		}
+		v.Populated = true
+		st.Volumes[name] = v
+		return nil
+	})
+}
+
+// DetachContainer removes the container reference from all volumes
+func (m *VsmManager) DetachContainer(containerId string) error {
+	return m.vsmStore.withLock(func(st *VolumeState) error {
+		for name, v := range st.Volumes {
+			idx := slices.Index(v.Containers, containerId)
+			if idx < 0 {
+				continue
+			}
+			v.Containers = slices.Delete(v.Containers, idx, idx+1)
+			st.Volumes[name] = v
+		}
+		return nil
+	})
+}
+
+func (m *VsmManager) GetVolumeList() ([]VolumeInfo, error) {
+	var volumeList []VolumeInfo
+	err := m.vsmStore.withRLock(func(st *VolumeState) error {
+		for _, v := range st.Volumes {
+			volumeList = append(volumeList, v)
+		}
+		return nil
+	})
+	return volumeList, err
+}
+
+func (m *VsmManager) GetVolumeByName(name string) (VolumeInfo, error) {
+	var volumeInfo VolumeInfo
+	err := m.vsmStore.withRLock(func(st *VolumeState) error {
+		v, ok := st.Volumes[name]
+		if !ok {
+			return fmt.Errorf("volume: %s not found", name)
+		}
+		volumeInfo = v
+		return nil
+	})
+	return volumeInfo, err
+}
+
+func (m *VsmManager) IsVolumeExist(name string) bool {
+	_, err := m.GetVolumeByName(name)
+	return err == nil
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"syscall"
 )
 
@@ -16,9 +18,19 @@ type FilesystemHandler interface {
 	Remove(name string) error
 	RemoveAll(path string) error
 	Rename(oldpath string, newpath string) error
+	Link(oldname string, newname string) error
+	Symlink(oldname string, newname string) error
+	Readlink(name string) (string, error)
 	IsNotExist(err error) bool
 	Flock(fd int, how int) error
 	Chmod(name string, mode os.FileMode) error
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	WalkDir(root string, fn fs.WalkDirFunc) error
+	DirSize(path string) int64
+	IsMountPoint(path string) bool
+	OpenRoot(root string) (RootHandler, error)
 }
 
 func NewFilesystemExecutor() *FilesystemExecutor {
@@ -63,6 +75,18 @@ func (s *FilesystemExecutor) Rename(oldpath string, newpath string) error {
 	return os.Rename(oldpath, newpath)
 }
 
+func (s *FilesystemExecutor) Link(oldname string, newname string) error {
+	return os.Link(oldname, newname)
+}
+
+func (s *FilesystemExecutor) Symlink(oldname string, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+func (s *FilesystemExecutor) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
 func (s *FilesystemExecutor) IsNotExist(err error) bool {
 	return os.IsNotExist(err)
 }
@@ -74,3 +98,56 @@ func (s *FilesystemExecutor) Flock(fd int, how int) error {
 func (s *FilesystemExecutor) Chmod(name string, mode os.FileMode) error {
 	return os.Chmod(name, mode)
 }
+
+func (s *FilesystemExecutor) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (s *FilesystemExecutor) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (s *FilesystemExecutor) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (s *FilesystemExecutor) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, fn)
+}
+
+// DirSize sums the size of regular files under the directory. unreadable entries are skipped
+func (s *FilesystemExecutor) DirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// IsMountPoint reports whether a filesystem is mounted on the path, comparing the device with the parent
+func (s *FilesystemExecutor) IsMountPoint(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	parent, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	pst, pok := parent.Sys().(*syscall.Stat_t)
+	return ok && pok && st.Dev != pst.Dev
+}
+
+func (s *FilesystemExecutor) OpenRoot(root string) (RootHandler, error) {
+	return NewRootExecutor(root)
+}
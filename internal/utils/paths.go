@@ -6,12 +6,14 @@ const (
 	ContainerRootDir = "/etc/raind/container"
 	ImageRootDir     = "/etc/raind/image"
 	LayerRootDir     = "/etc/raind/image/layers"
+	VolumeRootDir    = "/etc/raind/volume"
 
 	StoreDir      = "/etc/raind/store"
 	IpamStorePath = "/etc/raind/store/ipam.json"
 	CsmStorePath  = "/etc/raind/store/csm.json"
 	IlmStorePath  = "/etc/raind/store/ilm.json"
 	NpmStorePath  = "/etc/raind/store/npm.json"
+	VsmStorePath  = "/etc/raind/store/volume.json"
 
 	CgroupRuntimeDir         = "/sys/fs/cgroup/raind"
 	CgroupSubtreeControlPath = "/sys/fs/cgroup/raind/cgroup.subtree_control"
@@ -29,4 +31,9 @@ const (
 	AuditLogPath    = "/etc/raind/log/raind_audit.log"
 	UlogPath        = "/var/log/ulog/raind.jsonl"
 	EnrichedLogPath = "/var/log/raind/netflow.jsonl"
+
+	HostResolvConfPath    = "/etc/resolv.conf"
+	SystemdResolvConfPath = "/run/systemd/resolve/resolv.conf"
+
+	CapLastCapPath = "/proc/sys/kernel/cap_last_cap"
 )
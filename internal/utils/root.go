@@ -0,0 +1,383 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// RootHandler accesses files under a directory as if the directory were "/".
+// every path is resolved by the kernel (openat2 RESOLVE_IN_ROOT) relative to the root fd on each call,
+// so symlinks and ".." never lead outside even when the tree is modified concurrently.
+// the last path component is not followed unless noted
+type RootHandler interface {
+	Open(name string) (*os.File, error)
+	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	WalkDir(name string, fn fs.WalkDirFunc) error
+	Readlink(name string) (string, error)
+	MkdirAll(name string, perm os.FileMode) error
+	Symlink(oldname string, newname string) error
+	Link(oldname string, newname string) error
+	Mknod(name string, mode uint32, dev int) error
+	RemoveAll(name string) error
+	Lchown(name string, uid int, gid int) error
+	Chmod(name string, mode os.FileMode) error
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+	Close() error
+}
+
+func NewRootExecutor(root string) (*RootExecutor, error) {
+	fd, err := unix.Open(root, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: root, Err: err}
+	}
+	return &RootExecutor{root: root, fd: fd}, nil
+}
+
+type RootExecutor struct {
+	root string
+	fd   int
+}
+
+const rootResolve = unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_MAGICLINKS
+
+// openat resolves name in root. O_NOFOLLOW keeps the last component unresolved
+func (r *RootExecutor) openat(name string, flag int, perm uint32) (int, error) {
+	fd, err := unix.Openat2(r.fd, rootRel(name), &unix.OpenHow{
+		Flags:   uint64(flag | unix.O_CLOEXEC),
+		Mode:    uint64(perm),
+		Resolve: rootResolve,
+	})
+	if err != nil {
+		return -1, &os.PathError{Op: "openat2", Path: name, Err: err}
+	}
+	return fd, nil
+}
+
+// openParent opens the parent directory of name and returns it with the base name
+func (r *RootExecutor) openParent(name string) (int, string, error) {
+	rel := rootRel(name)
+	if rel == "." {
+		return -1, "", &os.PathError{Op: "open", Path: name, Err: unix.EINVAL}
+	}
+	fd, err := r.openat(filepath.Dir(rel), unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return -1, "", err
+	}
+	return fd, filepath.Base(rel), nil
+}
+
+func (r *RootExecutor) Open(name string) (*os.File, error) {
+	return r.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (r *RootExecutor) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	fd, err := r.openat(name, flag|unix.O_NOFOLLOW, syscallMode(perm))
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), filepath.Join(r.root, rootRel(name))), nil
+}
+
+func (r *RootExecutor) Stat(name string) (os.FileInfo, error) {
+	fd, err := r.openat(name, unix.O_PATH, 0)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), filepath.Join(r.root, rootRel(name)))
+	defer f.Close()
+	return f.Stat()
+}
+
+func (r *RootExecutor) Lstat(name string) (os.FileInfo, error) {
+	fd, err := r.openat(name, unix.O_PATH|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), filepath.Join(r.root, rootRel(name)))
+	defer f.Close()
+	return f.Stat()
+}
+
+// ReadDir lists the directory. entry info is taken with Lstat in root
+func (r *RootExecutor) ReadDir(name string) ([]os.DirEntry, error) {
+	fd, err := r.openat(name, unix.O_RDONLY|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), filepath.Join(r.root, rootRel(name)))
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+
+	entries := make([]os.DirEntry, 0, len(names))
+	for _, n := range names {
+		info, err := r.Lstat(filepath.Join(name, n))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return entries, nil
+}
+
+// WalkDir walks the tree at name in lexical order like filepath.WalkDir. paths passed to fn are relative to root
+func (r *RootExecutor) WalkDir(name string, fn fs.WalkDirFunc) error {
+	info, err := r.Lstat(name)
+	if err != nil {
+		err = fn(name, nil, err)
+	} else {
+		err = r.walkDir(name, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (r *RootExecutor) walkDir(name string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if err == filepath.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := r.ReadDir(name)
+	if err != nil {
+		if err = fn(name, d, err); err != nil {
+			if err == filepath.SkipDir && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, e := range entries {
+		if err := r.walkDir(filepath.Join(name, e.Name()), e, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RootExecutor) Readlink(name string) (string, error) {
+	dirfd, base, err := r.openParent(name)
+	if err != nil {
+		return "", err
+	}
+	defer unix.Close(dirfd)
+
+	buf := make([]byte, unix.PathMax)
+	n, err := unix.Readlinkat(dirfd, base, buf)
+	if err != nil {
+		return "", &os.PathError{Op: "readlinkat", Path: name, Err: err}
+	}
+	return string(buf[:n]), nil
+}
+
+// MkdirAll creates the directory and missing parents. existing symlinks in the path are followed inside root
+func (r *RootExecutor) MkdirAll(name string, perm os.FileMode) error {
+	current := "."
+	for _, c := range strings.Split(rootRel(name), "/") {
+		if c == "." {
+			continue
+		}
+		dirfd, err := r.openat(current, unix.O_PATH|unix.O_DIRECTORY, 0)
+		if err != nil {
+			return err
+		}
+		err = unix.Mkdirat(dirfd, c, syscallMode(perm))
+		unix.Close(dirfd)
+		if err != nil && err != unix.EEXIST {
+			return &os.PathError{Op: "mkdirat", Path: filepath.Join(current, c), Err: err}
+		}
+		current = filepath.Join(current, c)
+	}
+	// the result must be a directory
+	fd, err := r.openat(current, unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return err
+	}
+	return unix.Close(fd)
+}
+
+func (r *RootExecutor) Symlink(oldname string, newname string) error {
+	dirfd, base, err := r.openParent(newname)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(dirfd)
+	if err := unix.Symlinkat(oldname, dirfd, base); err != nil {
+		return &os.LinkError{Op: "symlinkat", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+// Link creates newname as a hard link to oldname. both are resolved in root
+func (r *RootExecutor) Link(oldname string, newname string) error {
+	olddirfd, oldbase, err := r.openParent(oldname)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(olddirfd)
+	newdirfd, newbase, err := r.openParent(newname)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(newdirfd)
+	if err := unix.Linkat(olddirfd, oldbase, newdirfd, newbase, 0); err != nil {
+		return &os.LinkError{Op: "linkat", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+func (r *RootExecutor) Mknod(name string, mode uint32, dev int) error {
+	dirfd, base, err := r.openParent(name)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(dirfd)
+	if err := unix.Mknodat(dirfd, base, mode, dev); err != nil {
+		return &os.PathError{Op: "mknodat", Path: name, Err: err}
+	}
+	return nil
+}
+
+// RemoveAll removes name and its children. symlinks are removed, not followed
+func (r *RootExecutor) RemoveAll(name string) error {
+	dirfd, base, err := r.openParent(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer unix.Close(dirfd)
+	if err := removeAllAt(dirfd, base); err != nil {
+		return &os.PathError{Op: "unlinkat", Path: name, Err: err}
+	}
+	return nil
+}
+
+func removeAllAt(dirfd int, name string) error {
+	err := unix.Unlinkat(dirfd, name, 0)
+	if err == nil || err == unix.ENOENT {
+		return nil
+	}
+	if err != unix.EISDIR {
+		return err
+	}
+
+	// directory: remove children through the directory fd
+	fd, err := unix.Openat(dirfd, name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if err == unix.ENOENT {
+			return nil
+		}
+		return err
+	}
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if err := removeAllAt(fd, n); err != nil {
+			return err
+		}
+	}
+	if err := unix.Unlinkat(dirfd, name, unix.AT_REMOVEDIR); err != nil && err != unix.ENOENT {
+		return err
+	}
+	return nil
+}
+
+func (r *RootExecutor) Lchown(name string, uid int, gid int) error {
+	dirfd, base, err := r.openParent(name)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(dirfd)
+	if err := unix.Fchownat(dirfd, base, uid, gid, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		return &os.PathError{Op: "fchownat", Path: name, Err: err}
+	}
+	return nil
+}
+
+// Chmod changes the mode of name. symlinks are rejected instead of followed.
+// the inode is pinned with an O_PATH fd and changed through its /proc/self/fd entry
+func (r *RootExecutor) Chmod(name string, mode os.FileMode) error {
+	fd, err := r.openat(name, unix.O_PATH|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return &os.PathError{Op: "fstat", Path: name, Err: err}
+	}
+	if st.Mode&unix.S_IFMT == unix.S_IFLNK {
+		return &os.PathError{Op: "chmod", Path: name, Err: unix.ELOOP}
+	}
+	if err := unix.Chmod(fmt.Sprintf("/proc/self/fd/%d", fd), syscallMode(mode)); err != nil {
+		return &os.PathError{Op: "chmod", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (r *RootExecutor) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	dirfd, base, err := r.openParent(name)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(dirfd)
+	ts := []unix.Timespec{unix.NsecToTimespec(atime.UnixNano()), unix.NsecToTimespec(mtime.UnixNano())}
+	if err := unix.UtimesNanoAt(dirfd, base, ts, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		return &os.PathError{Op: "utimensat", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (r *RootExecutor) Close() error {
+	return unix.Close(r.fd)
+}
+
+// rootRel converts name to the path relative to root. ".." is cleaned lexically and never goes above root
+func rootRel(name string) string {
+	return filepath.Clean("./" + strings.TrimPrefix(filepath.Clean("/"+name), "/"))
+}
+
+// syscallMode converts os.FileMode permission and special bits to the mode of syscalls
+func syscallMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= unix.S_ISUID
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= unix.S_ISGID
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= unix.S_ISVTX
+	}
+	return m
+}